@@ -0,0 +1,169 @@
+package bmp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+// fileHeaderSize is the size of the BITMAPFILEHEADER which precedes the DIB
+// header
+const fileHeaderSize = 14
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Reader understands a BMP byte stream
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	// dataOffset is the offset of the pixel data, from the file header
+	dataOffset uint32
+
+	header        *DIBHeader
+	iccProfile    *icc.Profile
+	linkedProfile string
+}
+
+// CheckHeader checks the byte stream to see if it starts with a BMP file
+// header, followed by a DIB header of a known size
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, fileHeaderSize+4)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a BMP
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if b[0] != 'B' || b[1] != 'M' {
+		return nil, nil
+	}
+	if _, ok := HeaderSize(b[fileHeaderSize:]); !ok {
+		return nil, nil
+	}
+	options.Logger.Debug("Matched BMP header", "offset", cur)
+	rdr := &Reader{r: reader.CreateLittleEndianReader(r, cur, options.Logger), options: options}
+	rdr.dataOffset = rdr.r.GetByteOrder().Uint32(b[10:])
+	return rdr, nil
+}
+
+// Header returns the DIB header, or nil if it has not been read
+func (r *Reader) Header() *DIBHeader {
+	return r.header
+}
+
+// DataOffset returns the offset of the pixel data from the start of the file
+func (r *Reader) DataOffset() uint32 {
+	return r.dataOffset
+}
+
+// ICCProfile returns the embedded ICC profile, or nil if there was none
+func (r *Reader) ICCProfile() *icc.Profile {
+	return r.iccProfile
+}
+
+// LinkedProfile returns the file name of a linked ICC profile, or an empty
+// string if there was none
+func (r *Reader) LinkedProfile() string {
+	return r.linkedProfile
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = r.r.SeekTo(fileHeaderSize); err != nil {
+		return 0, err
+	}
+	length, err := r.r.ReadUint32()
+	if err != nil {
+		return 0, err
+	}
+	if fileHeaderSize+int64(length) > size {
+		return 0, fmt.Errorf("%w: DIB header is %d bytes", common.ErrTruncatedSegment, length)
+	}
+	if err = r.r.SeekTo(fileHeaderSize); err != nil {
+		return 0, err
+	}
+	b, err := r.r.ReadBytes(int(length))
+	if err != nil {
+		return 0, err
+	}
+	if r.header, err = ParseDIBHeader(b); err != nil {
+		return 0, err
+	}
+	r.options.Logger.Debug("Read DIB header", "version", r.header.Version, "width", r.header.Width, "height", r.header.Height)
+
+	if err = r.readProfile(size); err != nil {
+		// The profile lies outside the headers, so a bad one costs only the
+		// profile.
+		r.options.Logger.Debug("Ignoring ICC profile", "err", err)
+	}
+
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	return cur - start, nil
+}
+
+// readProfile reads the embedded or linked ICC profile of a V5 header
+func (r *Reader) readProfile(size int64) error {
+	h := r.header
+	if h.ProfileSize == 0 || (h.ColorSpace != EmbeddedProfile && h.ColorSpace != LinkedProfile) {
+		return nil
+	}
+	offset := fileHeaderSize + int64(h.ProfileOffset)
+	if offset+int64(h.ProfileSize) > size {
+		return fmt.Errorf("%w: ICC profile at offset %d with size %d", common.ErrTruncatedSegment, offset, h.ProfileSize)
+	}
+	if err := r.r.SeekTo(offset); err != nil {
+		return err
+	}
+	b, err := r.r.ReadBytes(int(h.ProfileSize))
+	if err != nil {
+		return err
+	}
+
+	if h.ColorSpace == LinkedProfile {
+		// The file name is NUL-terminated, in the Windows-1252 code page, which
+		// is read as Latin-1.
+		if n := bytes.IndexByte(b, 0); n >= 0 {
+			b = b[:n]
+		}
+		r.linkedProfile = common.DecodeLatin1(b)
+		return nil
+	}
+	p, err := icc.Parse(b)
+	if err != nil {
+		return fmt.Errorf("Failed to read ICC profile: %w", err)
+	}
+	r.iccProfile = p
+	return nil
+}
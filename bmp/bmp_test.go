@@ -0,0 +1,184 @@
+package bmp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/bmp"
+	"github.com/object88/go-image-metadata/icc"
+)
+
+// dibHeader encodes a DIB header of the given size, with a 2x3 bottom-up
+// image at 2835 pixels per meter
+func dibHeader(size uint32, bitCount uint16) []byte {
+	le := binary.LittleEndian
+	b := make([]byte, size)
+	le.PutUint32(b, size)
+	if size == 12 {
+		le.PutUint16(b[4:], 2)
+		le.PutUint16(b[6:], 3)
+		le.PutUint16(b[8:], 1)
+		le.PutUint16(b[10:], bitCount)
+		return b
+	}
+	le.PutUint32(b[4:], 2)
+	le.PutUint32(b[8:], 3)
+	le.PutUint16(b[12:], 1)
+	le.PutUint16(b[14:], bitCount)
+	le.PutUint32(b[24:], 2835)
+	le.PutUint32(b[28:], 2835)
+	return b
+}
+
+// buildBmp adds the file header before the DIB header, and the profile after
+// it
+func buildBmp(dib []byte, profile []byte) []byte {
+	b := []byte{'B', 'M'}
+	b = binary.LittleEndian.AppendUint32(b, uint32(14+len(dib)+len(profile)))
+	b = append(b, 0, 0, 0, 0)
+	b = binary.LittleEndian.AppendUint32(b, uint32(14+len(dib)))
+	b = append(b, dib...)
+	return append(b, profile...)
+}
+
+func Test_Headers(t *testing.T) {
+	v4 := dibHeader(108, 32)
+	binary.LittleEndian.PutUint32(v4[16:], uint32(bmp.BitFields))
+	binary.LittleEndian.PutUint32(v4[40:], 0x00ff0000)
+	binary.LittleEndian.PutUint32(v4[52:], 0xff000000)
+	binary.LittleEndian.PutUint32(v4[56:], uint32(bmp.SRGB))
+
+	topDown := dibHeader(40, 24)
+	binary.LittleEndian.PutUint32(topDown[8:], uint32(0xfffffffd))
+
+	var tcs = []struct {
+		name        string
+		dib         []byte
+		version     bmp.Version
+		bitCount    uint16
+		compression bmp.Compression
+		colorSpace  bmp.ColorSpace
+		topDown     bool
+		dpi         bool
+	}{
+		{"core", dibHeader(12, 8), bmp.CoreHeader, 8, bmp.RGB, bmp.CalibratedRGB, false, false},
+		{"info", dibHeader(40, 24), bmp.InfoHeader, 24, bmp.RGB, bmp.CalibratedRGB, false, true},
+		{"top-down", topDown, bmp.InfoHeader, 24, bmp.RGB, bmp.CalibratedRGB, true, true},
+		{"V4", v4, bmp.V4Header, 32, bmp.BitFields, bmp.SRGB, false, true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(buildBmp(tc.dib, nil)))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			if _, err = ir.Read(); err != nil {
+				t.Fatalf("Error while reading DIB header: %s\n", err)
+			}
+			h := ir.(*bmp.Reader).Header()
+			if h.Version != tc.version || h.Width != 2 || h.Height != 3 || h.BitCount != tc.bitCount {
+				t.Fatalf("Expected %s 2x3 with %d bits; got %+v", tc.version, tc.bitCount, h)
+			}
+			if h.Compression != tc.compression || h.ColorSpace != tc.colorSpace || h.TopDown != tc.topDown {
+				t.Fatalf("Expected %s compression, %s color space, top-down %t; got %+v", tc.compression, tc.colorSpace, tc.topDown, h)
+			}
+			if x, y, ok := h.DPI(); ok != tc.dpi || (ok && (int(x+0.5) != 72 || int(y+0.5) != 72)) {
+				t.Fatalf("Expected DPI %t; got %f x %f, %t", tc.dpi, x, y, ok)
+			}
+		})
+	}
+}
+
+func Test_InvalidHeight(t *testing.T) {
+	dib := dibHeader(40, 24)
+	binary.LittleEndian.PutUint32(dib[8:], 0x80000000)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(buildBmp(dib, nil)))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err == nil {
+		t.Fatalf("Expected error for height 0x80000000; got %+v", ir.(*bmp.Reader).Header())
+	}
+}
+
+func Test_Profiles(t *testing.T) {
+	profile := make([]byte, 132)
+	binary.BigEndian.PutUint32(profile, 132)
+	profile[8] = 4
+	copy(profile[12:], "mntrRGB XYZ ")
+	copy(profile[36:], "acsp")
+
+	v5 := func(colorSpace bmp.ColorSpace, size int) []byte {
+		b := dibHeader(124, 24)
+		binary.LittleEndian.PutUint32(b[56:], uint32(colorSpace))
+		binary.LittleEndian.PutUint32(b[108:], 4)
+		binary.LittleEndian.PutUint32(b[112:], 124)
+		binary.LittleEndian.PutUint32(b[116:], uint32(size))
+		return b
+	}
+
+	t.Run("embedded", func(t *testing.T) {
+		ir, err := metadata.ReadHeader(bytes.NewReader(buildBmp(v5(bmp.EmbeddedProfile, len(profile)), profile)))
+		if err != nil {
+			t.Fatalf("Error while reading header: %s\n", err)
+		}
+		if _, err = ir.Read(); err != nil {
+			t.Fatalf("Error while reading DIB header: %s\n", err)
+		}
+		r := ir.(*bmp.Reader)
+		if p := r.ICCProfile(); p == nil || p.Class != icc.DisplayClass {
+			t.Fatalf("Expected display profile; got %v", p)
+		}
+		if i, ok := r.Header().RenderingIntent(); !ok || i != icc.Perceptual {
+			t.Fatalf("Expected perceptual intent; got %s", i)
+		}
+	})
+
+	t.Run("linked", func(t *testing.T) {
+		name := []byte("C:\\Profiles\\caf\xe9.icc\x00")
+		ir, err := metadata.ReadHeader(bytes.NewReader(buildBmp(v5(bmp.LinkedProfile, len(name)), name)))
+		if err != nil {
+			t.Fatalf("Error while reading header: %s\n", err)
+		}
+		if _, err = ir.Read(); err != nil {
+			t.Fatalf("Error while reading DIB header: %s\n", err)
+		}
+		r := ir.(*bmp.Reader)
+		if l := r.LinkedProfile(); l != "C:\\Profiles\\café.icc" || r.ICCProfile() != nil {
+			t.Fatalf("Expected linked profile 'C:\\Profiles\\café.icc'; got '%s'", l)
+		}
+	})
+	bad := make([]byte, len(profile))
+	copy(bad, profile)
+	copy(bad[36:], "nope")
+	var tcs = []struct {
+		name string
+		b    []byte
+	}{
+		{"malformed", buildBmp(v5(bmp.EmbeddedProfile, len(bad)), bad)},
+		{"past the end", buildBmp(v5(bmp.EmbeddedProfile, len(profile)+1), profile)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(tc.b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			if _, err = ir.Read(); err != nil {
+				t.Fatalf("Expected the profile to be skipped; got '%s'", err)
+			}
+			r := ir.(*bmp.Reader)
+			if r.ICCProfile() != nil {
+				t.Fatalf("Expected no ICC profile")
+			}
+			if h := r.Header(); h.Width != 2 || h.Height != 3 {
+				t.Fatalf("Expected 2x3 DIB header; got %+v", h)
+			}
+		})
+	}
+}
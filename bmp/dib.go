@@ -0,0 +1,302 @@
+package bmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+)
+
+// Version identifies a DIB header by its size
+type Version int
+
+const (
+	// CoreHeader is the 12 byte BITMAPCOREHEADER, also used by OS/2 1.x
+	CoreHeader Version = iota
+
+	// OS2Header is the 16 or 64 byte OS/2 2.x BITMAPCOREHEADER2
+	OS2Header
+
+	// InfoHeader is the 40 byte BITMAPINFOHEADER
+	InfoHeader
+
+	// V2Header is the 52 byte BITMAPV2INFOHEADER, which adds RGB masks
+	V2Header
+
+	// V3Header is the 56 byte BITMAPV3INFOHEADER, which adds an alpha mask
+	V3Header
+
+	// V4Header is the 108 byte BITMAPV4HEADER, which adds a color space
+	V4Header
+
+	// V5Header is the 124 byte BITMAPV5HEADER, which adds an ICC profile
+	V5Header
+)
+
+var versions = [...]string{
+	"BITMAPCOREHEADER",
+	"OS22XBITMAPHEADER",
+	"BITMAPINFOHEADER",
+	"BITMAPV2INFOHEADER",
+	"BITMAPV3INFOHEADER",
+	"BITMAPV4HEADER",
+	"BITMAPV5HEADER",
+}
+
+func (v Version) String() string {
+	return versions[v]
+}
+
+// headerVersions maps the size of a DIB header to its version
+var headerVersions = map[uint32]Version{
+	12:  CoreHeader,
+	16:  OS2Header,
+	40:  InfoHeader,
+	52:  V2Header,
+	56:  V3Header,
+	64:  OS2Header,
+	108: V4Header,
+	124: V5Header,
+}
+
+// Compression is the biCompression field
+type Compression uint32
+
+const (
+	// RGB is uncompressed
+	RGB Compression = 0
+
+	// RLE8 is run-length encoded, with 8 bits per pixel
+	RLE8 Compression = 1
+
+	// RLE4 is run-length encoded, with 4 bits per pixel
+	RLE4 Compression = 2
+
+	// BitFields is uncompressed, with color masks
+	BitFields Compression = 3
+
+	// JPEG means the pixel data is a JPEG image
+	JPEG Compression = 4
+
+	// PNG means the pixel data is a PNG image
+	PNG Compression = 5
+
+	// AlphaBitFields is uncompressed, with color and alpha masks
+	AlphaBitFields Compression = 6
+
+	// CMYK is uncompressed CMYK
+	CMYK Compression = 11
+
+	// CMYKRLE8 is run-length encoded CMYK, with 8 bits per pixel
+	CMYKRLE8 Compression = 12
+
+	// CMYKRLE4 is run-length encoded CMYK, with 4 bits per pixel
+	CMYKRLE4 Compression = 13
+)
+
+var compressions = map[Compression]string{
+	RGB:            "RGB",
+	RLE8:           "RLE8",
+	RLE4:           "RLE4",
+	BitFields:      "bitfields",
+	JPEG:           "JPEG",
+	PNG:            "PNG",
+	AlphaBitFields: "alpha bitfields",
+	CMYK:           "CMYK",
+	CMYKRLE8:       "CMYK RLE8",
+	CMYKRLE4:       "CMYK RLE4",
+}
+
+func (c Compression) String() string {
+	if s, ok := compressions[c]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// ColorSpace is the bV4CSType field, which is a FourCC for all but calibrated
+// RGB
+type ColorSpace uint32
+
+const (
+	// CalibratedRGB uses the endpoints and gamma values of the header
+	CalibratedRGB ColorSpace = 0
+
+	// SRGB is the sRGB color space
+	SRGB ColorSpace = 0x73524742
+
+	// WindowsColorSpace is the system default color space
+	WindowsColorSpace ColorSpace = 0x57696e20
+
+	// LinkedProfile names an ICC profile file
+	LinkedProfile ColorSpace = 0x4c494e4b
+
+	// EmbeddedProfile is followed by an ICC profile
+	EmbeddedProfile ColorSpace = 0x4d424544
+)
+
+var colorSpaces = map[ColorSpace]string{
+	CalibratedRGB:     "calibrated RGB",
+	SRGB:              "sRGB",
+	WindowsColorSpace: "Windows",
+	LinkedProfile:     "linked profile",
+	EmbeddedProfile:   "embedded profile",
+}
+
+func (c ColorSpace) String() string {
+	if s, ok := colorSpaces[c]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// intents maps the bV5Intent LCS_GM_* values to ICC rendering intents
+var intents = map[uint32]icc.RenderingIntent{
+	1: icc.Saturation,
+	2: icc.RelativeColorimetric,
+	4: icc.Perceptual,
+	8: icc.AbsoluteColorimetric,
+}
+
+// DIBHeader is the device-independent bitmap header which follows the BMP
+// file header, and starts a DIB icon image.  Fields which are not present in
+// the header's version are zero.
+type DIBHeader struct {
+	Version Version
+
+	// Size is the size of the header in bytes
+	Size uint32
+
+	Width  int32
+	Height int32
+
+	// TopDown is true if the rows are stored from the top, which is
+	// signalled by a negative height.  Height is always positive.
+	TopDown bool
+
+	Planes   uint16
+	BitCount uint16
+
+	Compression Compression
+	ImageSize   uint32
+
+	XPixelsPerMeter int32
+	YPixelsPerMeter int32
+
+	ColorsUsed      uint32
+	ColorsImportant uint32
+
+	RedMask   uint32
+	GreenMask uint32
+	BlueMask  uint32
+	AlphaMask uint32
+
+	ColorSpace ColorSpace
+
+	// Intent is the raw LCS_GM_* rendering intent
+	Intent uint32
+
+	// ProfileOffset is the offset of the ICC profile data from the start of
+	// the DIB header, and ProfileSize its length
+	ProfileOffset uint32
+	ProfileSize   uint32
+}
+
+// DPI converts the pixels per meter to dots per inch, and returns false if
+// the resolution is not given
+func (h *DIBHeader) DPI() (float64, float64, bool) {
+	if h.XPixelsPerMeter <= 0 || h.YPixelsPerMeter <= 0 {
+		return 0, 0, false
+	}
+	return float64(h.XPixelsPerMeter) * 0.0254, float64(h.YPixelsPerMeter) * 0.0254, true
+}
+
+// RenderingIntent converts Intent to an ICC rendering intent, and returns
+// false if it is not set
+func (h *DIBHeader) RenderingIntent() (icc.RenderingIntent, bool) {
+	i, ok := intents[h.Intent]
+	return i, ok
+}
+
+// HeaderSize reads the size of the DIB header at the start of b, and returns
+// false if it does not match a known version
+func HeaderSize(b []byte) (uint32, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	size := binary.LittleEndian.Uint32(b)
+	_, ok := headerVersions[size]
+	return size, ok
+}
+
+// ParseDIBHeader parses the DIB header at the start of b
+func ParseDIBHeader(b []byte) (*DIBHeader, error) {
+	size, ok := HeaderSize(b)
+	if !ok {
+		return nil, fmt.Errorf("Unknown DIB header size %d", size)
+	}
+	if uint32(len(b)) < size {
+		return nil, fmt.Errorf("%w: DIB header is %d bytes; expected %d", common.ErrTruncatedSegment, len(b), size)
+	}
+
+	le := binary.LittleEndian
+	h := &DIBHeader{Version: headerVersions[size], Size: size}
+	if h.Version == CoreHeader {
+		h.Width = int32(le.Uint16(b[4:]))
+		h.Height = int32(le.Uint16(b[6:]))
+		h.Planes = le.Uint16(b[8:])
+		h.BitCount = le.Uint16(b[10:])
+		return h, nil
+	}
+
+	h.Width = int32(le.Uint32(b[4:]))
+	h.Height = int32(le.Uint32(b[8:]))
+	if h.Height == math.MinInt32 {
+		// The height can't be negated to be positive.
+		return nil, fmt.Errorf("Invalid DIB height %d", h.Height)
+	}
+	if h.Height < 0 {
+		h.TopDown = true
+		h.Height = -h.Height
+	}
+	h.Planes = le.Uint16(b[12:])
+	h.BitCount = le.Uint16(b[14:])
+	if size < 40 {
+		// The short OS/2 header stops here
+		return h, nil
+	}
+
+	h.Compression = Compression(le.Uint32(b[16:]))
+	h.ImageSize = le.Uint32(b[20:])
+	h.XPixelsPerMeter = int32(le.Uint32(b[24:]))
+	h.YPixelsPerMeter = int32(le.Uint32(b[28:]))
+	h.ColorsUsed = le.Uint32(b[32:])
+	h.ColorsImportant = le.Uint32(b[36:])
+	if h.Version == OS2Header || h.Version == InfoHeader {
+		return h, nil
+	}
+
+	h.RedMask = le.Uint32(b[40:])
+	h.GreenMask = le.Uint32(b[44:])
+	h.BlueMask = le.Uint32(b[48:])
+	if h.Version == V2Header {
+		return h, nil
+	}
+	h.AlphaMask = le.Uint32(b[52:])
+	if h.Version == V3Header {
+		return h, nil
+	}
+
+	// The endpoints and gamma values follow, for calibrated RGB
+	h.ColorSpace = ColorSpace(le.Uint32(b[56:]))
+	if h.Version == V4Header {
+		return h, nil
+	}
+
+	h.Intent = le.Uint32(b[108:])
+	h.ProfileOffset = le.Uint32(b[112:])
+	h.ProfileSize = le.Uint32(b[116:])
+	return h, nil
+}
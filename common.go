@@ -4,6 +4,11 @@ import "github.com/object88/go-image-metadata/tags"
 
 // ImageReader reads the image tags and stuff.
 type ImageReader interface {
-	Read() map[uint16]tags.Tag
-	ReadPartial(foundTags *map[uint16]tags.Tag) int64
+	// Read returns all tags found in the byte stream.  If an error is
+	// returned, the tree contains the tags which were read before the failure.
+	Read() (*tags.Tree, error)
+
+	// ReadPartial adds the IFDs found in the byte stream to tree, and returns
+	// the number of bytes consumed from the byte stream.
+	ReadPartial(tree *tags.Tree) (int64, error)
 }
@@ -44,6 +44,10 @@ const (
 
 	// Dfloat is a signed 8 byte floating point number
 	Dfloat
+
+	// Ifd is an unsigned long (4 bytes) holding the offset of an IFD, as
+	// introduced by TIFF-EP
+	Ifd
 )
 
 // DataFormatSizes maps a DataFormat to the number of bytes a single instance
@@ -61,6 +65,7 @@ var DataFormatSizes = map[DataFormat]uint32{
 	Srational:   8,
 	Sfloat:      4,
 	Dfloat:      8,
+	Ifd:         4,
 }
 
 var dataFormats = [...]string{
@@ -77,8 +82,12 @@ var dataFormats = [...]string{
 	"signed rational",
 	"single float",
 	"double float",
+	"ifd",
 }
 
 func (df DataFormat) String() string {
+	if int(df) >= len(dataFormats) {
+		return "unknown"
+	}
 	return dataFormats[df]
 }
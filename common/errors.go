@@ -0,0 +1,25 @@
+package common
+
+import "errors"
+
+var (
+	// ErrBadChecksum indicates that a chunk's stored checksum does not match
+	// its content.
+	ErrBadChecksum = errors.New("Bad checksum")
+
+	// ErrBadIfdOffset indicates that an IFD offset points outside of the byte
+	// stream, or back at an IFD which has already been read.
+	ErrBadIfdOffset = errors.New("Bad IFD offset")
+
+	// ErrBadMarker indicates that a JPEG marker segment did not start with
+	// 0xff.
+	ErrBadMarker = errors.New("Bad marker")
+
+	// ErrTruncatedSegment indicates that the byte stream ended before a
+	// segment, IFD or value could be read in full.
+	ErrTruncatedSegment = errors.New("Truncated segment")
+
+	// ErrUnknownFormat indicates that no registered CheckHeader method
+	// accepted the byte stream.
+	ErrUnknownFormat = errors.New("Unknown file format")
+)
@@ -0,0 +1,12 @@
+package common
+
+import "strings"
+
+// DecodeLatin1 decodes ISO 8859-1, which maps each byte to the same code point
+func DecodeLatin1(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		s.WriteRune(rune(c))
+	}
+	return s.String()
+}
@@ -0,0 +1,13 @@
+package metadata
+
+import "github.com/object88/go-image-metadata/common"
+
+// These errors may be returned (possibly wrapped) from ReadHeader and from the
+// ImageReader methods; test for them with errors.Is.
+var (
+	ErrBadChecksum      = common.ErrBadChecksum
+	ErrBadIfdOffset     = common.ErrBadIfdOffset
+	ErrBadMarker        = common.ErrBadMarker
+	ErrTruncatedSegment = common.ErrTruncatedSegment
+	ErrUnknownFormat    = common.ErrUnknownFormat
+)
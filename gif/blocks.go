@@ -0,0 +1,317 @@
+package gif
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/xmp"
+)
+
+// xmpTrailerStart begins the "magic trailer" which XMP writers append to the
+// packet, so that readers which expect data sub-blocks can skip it
+var xmpTrailerStart = []byte{0x01, 0xff, 0xfe}
+
+// Disposal is the method used to dispose of a frame before the next one is
+// drawn
+type Disposal int
+
+const (
+	// Unspecified leaves the choice to the decoder
+	Unspecified Disposal = iota
+
+	// DoNotDispose leaves the frame in place
+	DoNotDispose
+
+	// RestoreBackground clears the frame's area to the background color
+	RestoreBackground
+
+	// RestorePrevious restores the frame's area to what was there before it
+	RestorePrevious
+)
+
+var disposals = [...]string{
+	"unspecified",
+	"do not dispose",
+	"restore background",
+	"restore previous",
+}
+
+func (d Disposal) String() string {
+	if d < 0 || int(d) >= len(disposals) {
+		return "unknown"
+	}
+	return disposals[d]
+}
+
+// Header holds the version and the logical screen descriptor
+type Header struct {
+	// Version is "87a" or "89a"
+	Version string
+
+	Width  uint16
+	Height uint16
+
+	// GlobalColorTableSize is the number of entries in the global color table,
+	// or 0 if there is none
+	GlobalColorTableSize int
+
+	// ColorResolution is the number of bits per primary color of the original
+	// image
+	ColorResolution int
+
+	// Sorted is true if the global color table is sorted by importance
+	Sorted bool
+
+	BackgroundIndex uint8
+
+	// AspectRatio is the raw pixel aspect ratio byte; 0 means no information
+	AspectRatio uint8
+}
+
+// PixelAspectRatio returns the width of a pixel divided by its height, and
+// false if the aspect ratio is not given
+func (h *Header) PixelAspectRatio() (float64, bool) {
+	if h.AspectRatio == 0 {
+		return 0, false
+	}
+	return (float64(h.AspectRatio) + 15) / 64, true
+}
+
+// Frame is an image descriptor, with the graphic control extension which
+// precedes it
+type Frame struct {
+	Left   uint16
+	Top    uint16
+	Width  uint16
+	Height uint16
+
+	// LocalColorTableSize is the number of entries in the local color table,
+	// or 0 if the frame uses the global one
+	LocalColorTableSize int
+
+	Interlaced bool
+
+	Delay    time.Duration
+	Disposal Disposal
+
+	// UserInput is true if the frame waits for user input before continuing
+	UserInput bool
+
+	// Transparent is true if TransparentIndex is a transparent color
+	Transparent      bool
+	TransparentIndex uint8
+}
+
+// Header returns the version and logical screen descriptor
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Frames returns the image descriptors, in stream order
+func (r *Reader) Frames() []Frame {
+	return r.frames
+}
+
+// Duration returns the sum of the frame delays
+func (r *Reader) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range r.frames {
+		d += f.Delay
+	}
+	return d
+}
+
+// LoopCount returns the number of times to loop from the NETSCAPE2.0
+// extension, where 0 means forever, and false if there was no such extension
+func (r *Reader) LoopCount() (uint16, bool) {
+	if r.loopCount == nil {
+		return 0, false
+	}
+	return *r.loopCount, true
+}
+
+// Comments returns the comment extensions, in stream order
+func (r *Reader) Comments() []string {
+	return r.comments
+}
+
+// XMP returns the packet from the XMP application extension, or nil if there
+// was none
+func (r *Reader) XMP() *xmp.Packet {
+	return r.xmp
+}
+
+// colorTableSize converts the 3 bit size field of a packed byte to a number
+// of entries
+func colorTableSize(packed uint8) int {
+	return 1 << (packed&0x07 + 1)
+}
+
+func (r *Reader) readScreenDescriptor() error {
+	b, err := r.r.ReadBytes(7)
+	if err != nil {
+		return fmt.Errorf("%w: logical screen descriptor", common.ErrTruncatedSegment)
+	}
+	order := r.r.GetByteOrder()
+	h := r.header
+	h.Width = order.Uint16(b[0:])
+	h.Height = order.Uint16(b[2:])
+	h.ColorResolution = int(b[4]>>4&0x07) + 1
+	h.Sorted = b[4]&0x08 != 0
+	h.BackgroundIndex = b[5]
+	h.AspectRatio = b[6]
+	if b[4]&0x80 != 0 {
+		h.GlobalColorTableSize = colorTableSize(b[4])
+		if err = r.skipColorTable(h.GlobalColorTableSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipColorTable moves past a color table with the provided number of
+// entries.  Discarding doesn't fail past the end of the stream, so the
+// position is checked against its size.
+func (r *Reader) skipColorTable(entries int) error {
+	if err := r.r.Discard(int64(3 * entries)); err != nil {
+		return err
+	}
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return err
+	}
+	if cur > size {
+		return fmt.Errorf("%w: color table of %d entries ends at offset %d", common.ErrTruncatedSegment, entries, cur)
+	}
+	return nil
+}
+
+func (r *Reader) readGraphicControl() error {
+	b, err := r.readSubBlocks()
+	if err != nil {
+		return err
+	}
+	if len(b) < 4 {
+		return fmt.Errorf("%w: graphic control extension is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	r.control = &Frame{
+		Disposal:         Disposal(b[0] >> 2 & 0x07),
+		UserInput:        b[0]&0x02 != 0,
+		Transparent:      b[0]&0x01 != 0,
+		Delay:            time.Duration(r.r.GetByteOrder().Uint16(b[1:])) * 10 * time.Millisecond,
+		TransparentIndex: b[3],
+	}
+	return nil
+}
+
+func (r *Reader) readImage() error {
+	b, err := r.r.ReadBytes(9)
+	if err != nil {
+		return fmt.Errorf("%w: image descriptor", common.ErrTruncatedSegment)
+	}
+
+	// The graphic control extension applies to this image only.
+	f := Frame{}
+	if r.control != nil {
+		f = *r.control
+		r.control = nil
+	}
+	order := r.r.GetByteOrder()
+	f.Left = order.Uint16(b[0:])
+	f.Top = order.Uint16(b[2:])
+	f.Width = order.Uint16(b[4:])
+	f.Height = order.Uint16(b[6:])
+	f.Interlaced = b[8]&0x40 != 0
+	if b[8]&0x80 != 0 {
+		f.LocalColorTableSize = colorTableSize(b[8])
+		if err = r.skipColorTable(f.LocalColorTableSize); err != nil {
+			return err
+		}
+	}
+	r.frames = append(r.frames, f)
+
+	// Skip the LZW minimum code size, and then the image data.
+	if err = r.r.Discard(1); err != nil {
+		return err
+	}
+	return r.skipSubBlocks()
+}
+
+func (r *Reader) readApplication() error {
+	id, err := r.r.ReadUint8()
+	if err != nil {
+		return err
+	}
+	if id != 11 {
+		// Not a standard application identifier block; skip it, and whatever
+		// follows.
+		if err = r.r.Discard(int64(id)); err != nil {
+			return err
+		}
+		return r.skipSubBlocks()
+	}
+	b, err := r.r.ReadBytes(11)
+	if err != nil {
+		return err
+	}
+	app := string(b)
+	r.options.Logger.Debug("Read application extension", "identifier", app)
+
+	switch app {
+	case "NETSCAPE2.0", "ANIMEXTS1.0":
+		data, err := r.readSubBlocks()
+		if err != nil {
+			return err
+		}
+		if len(data) >= 3 && data[0] == 1 {
+			n := r.r.GetByteOrder().Uint16(data[1:])
+			r.loopCount = &n
+		}
+		return nil
+	case "XMP DataXMP":
+		return r.readXMP()
+	}
+	return r.skipSubBlocks()
+}
+
+// readXMP reads the XMP packet, which is stored as raw bytes rather than
+// data sub-blocks.  Reading it as sub-blocks, keeping the length bytes,
+// reconstructs the raw bytes, which end with the magic trailer.  Only a failure
+// to read the extension is returned; a malformed packet is logged and skipped.
+func (r *Reader) readXMP() error {
+	raw := []byte{}
+	for {
+		n, err := r.r.ReadUint8()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		data, err := r.r.ReadBytes(int(n))
+		if err != nil {
+			return err
+		}
+		raw = append(raw, n)
+		raw = append(raw, data...)
+	}
+
+	if k := bytes.LastIndex(raw, xmpTrailerStart); k >= 0 {
+		raw = raw[:k]
+	}
+	p, err := xmp.Parse(raw)
+	if err != nil {
+		// The extension has been read in full, so a malformed packet costs only
+		// the packet.
+		r.options.Logger.Debug("Ignoring XMP extension", "err", err)
+		return nil
+	}
+	r.xmp = p
+	return nil
+}
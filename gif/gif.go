@@ -0,0 +1,191 @@
+package gif
+
+import (
+	"fmt"
+	"io"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+)
+
+// Block introducers
+const (
+	extensionIntroducer = 0x21
+	imageSeparator      = 0x2c
+	trailer             = 0x3b
+)
+
+// Extension labels
+const (
+	graphicControlLabel = 0xf9
+	commentLabel        = 0xfe
+	applicationLabel    = 0xff
+)
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Reader understands a GIF byte stream
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	header    *Header
+	frames    []Frame
+	loopCount *uint16
+	comments  []string
+	xmp       *xmp.Packet
+
+	// control is the graphic control extension which applies to the next
+	// image
+	control *Frame
+}
+
+// CheckHeader checks the byte stream to see if it starts with a GIF87a or
+// GIF89a signature
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 6)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a GIF
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if string(b[0:3]) != "GIF" || (string(b[3:6]) != "87a" && string(b[3:6]) != "89a") {
+		return nil, nil
+	}
+	options.Logger.Debug("Matched GIF header", "offset", cur, "version", string(b[3:6]))
+	return &Reader{r: reader.CreateLittleEndianReader(r, cur, options.Logger), options: options, header: &Header{Version: string(b[3:6])}}, nil
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+// ReadPartial reads the blocks of the GIF.  Comments and application
+// extensions may follow the image data, so the whole stream is read, but
+// image data is skipped without being decompressed.
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = r.readScreenDescriptor(); err != nil {
+		return 0, err
+	}
+
+	// Loop over blocks
+	for {
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return 0, err
+		}
+		if cur >= size {
+			// Some encoders omit the trailer.
+			r.options.Logger.Debug("Reached end of stream without a trailer", "offset", cur)
+			break
+		}
+
+		b, err := r.r.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+		if b == trailer {
+			break
+		}
+
+		switch b {
+		case extensionIntroducer:
+			err = r.readExtension()
+		case imageSeparator:
+			err = r.readImage()
+		default:
+			err = fmt.Errorf("Unknown block introducer 0x%02x at offset %d", b, cur)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	return cur - start, nil
+}
+
+func (r *Reader) readExtension() error {
+	label, err := r.r.ReadUint8()
+	if err != nil {
+		return err
+	}
+	r.options.Logger.Debug("Read extension", "label", fmt.Sprintf("0x%02x", label))
+
+	switch label {
+	case graphicControlLabel:
+		return r.readGraphicControl()
+	case commentLabel:
+		b, err := r.readSubBlocks()
+		if err != nil {
+			return err
+		}
+		r.comments = append(r.comments, string(b))
+		return nil
+	case applicationLabel:
+		return r.readApplication()
+	}
+	_, err = r.readSubBlocks()
+	return err
+}
+
+// readSubBlocks reads a sequence of data sub-blocks, up to and including the
+// block terminator, and returns their concatenated data
+func (r *Reader) readSubBlocks() ([]byte, error) {
+	b := []byte{}
+	for {
+		n, err := r.r.ReadUint8()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return b, nil
+		}
+		data, err := r.r.ReadBytes(int(n))
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, data...)
+	}
+}
+
+// skipSubBlocks moves past a sequence of data sub-blocks, such as image data
+func (r *Reader) skipSubBlocks() error {
+	for {
+		n, err := r.r.ReadUint8()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if err = r.r.Discard(int64(n)); err != nil {
+			return err
+		}
+	}
+}
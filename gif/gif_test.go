@@ -0,0 +1,170 @@
+package gif_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/gif"
+)
+
+// subBlocks splits data into sub-blocks, and adds the block terminator
+func subBlocks(data []byte, size int) []byte {
+	b := []byte{}
+	for len(data) > 0 {
+		n := min(size, len(data))
+		b = append(b, byte(n))
+		b = append(b, data[:n]...)
+		data = data[n:]
+	}
+	return append(b, 0)
+}
+
+// image encodes an image descriptor, with a local color table of 2 entries
+// and some image data
+func image(left, width uint16) []byte {
+	b := []byte{0x2c, byte(left), byte(left >> 8), 0, 0, byte(width), byte(width >> 8), 1, 0, 0x80}
+	b = append(b, 0, 0, 0, 0xff, 0xff, 0xff, 2)
+	return append(b, subBlocks(bytes.Repeat([]byte{0x55}, 300), 255)...)
+}
+
+func graphicControl(packed uint8, delay uint16) []byte {
+	return []byte{0x21, 0xf9, 4, packed, byte(delay), byte(delay >> 8), 7, 0}
+}
+
+func xmpExtension(packet string) []byte {
+	b := append([]byte{0x21, 0xff, 11}, "XMP DataXMP"...)
+	b = append(b, packet...)
+	b = append(b, 0x01)
+	for k := 0xff; k >= 0; k-- {
+		b = append(b, byte(k))
+	}
+	return append(b, 0)
+}
+
+func Test_Blocks(t *testing.T) {
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:CreatorTool="Test"/></rdf:RDF></x:xmpmeta>`
+
+	b := []byte("GIF89a")
+	b = append(b, 0x40, 0x01, 0xf0, 0x00, 0xf1, 3, 49)
+	b = append(b, make([]byte, 3*4)...)
+	b = append(b, 0x21, 0xff, 11)
+	b = append(b, "NETSCAPE2.0"...)
+	b = append(b, 3, 1, 0, 0, 0)
+	b = append(b, graphicControl(0x05, 50)...)
+	b = append(b, image(0, 320)...)
+	b = append(b, graphicControl(0x0c, 150)...)
+	b = append(b, image(16, 100)...)
+	b = append(b, 0x21, 0xfe)
+	b = append(b, subBlocks([]byte("Made with a test"), 5)...)
+	b = append(b, 0x21, 0x01, 12)
+	b = append(b, make([]byte, 12)...)
+	b = append(b, subBlocks([]byte("plain text"), 255)...)
+	b = append(b, xmpExtension(packet)...)
+	b = append(b, 0x3b)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err != nil {
+		t.Fatalf("Error while reading blocks: %s\n", err)
+	}
+	r := ir.(*gif.Reader)
+
+	h := r.Header()
+	if h.Version != "89a" || h.Width != 320 || h.Height != 240 || h.GlobalColorTableSize != 4 || h.ColorResolution != 8 {
+		t.Fatalf("Expected 89a 320x240 header with 4 global colors; got %+v", h)
+	}
+	if a, ok := h.PixelAspectRatio(); !ok || a != 1 {
+		t.Fatalf("Expected square pixels; got %f", a)
+	}
+
+	expected := []gif.Frame{
+		{Width: 320, Height: 1, LocalColorTableSize: 2, Delay: 500 * time.Millisecond, Disposal: gif.DoNotDispose, Transparent: true, TransparentIndex: 7},
+		{Left: 16, Width: 100, Height: 1, LocalColorTableSize: 2, Delay: 1500 * time.Millisecond, Disposal: gif.RestorePrevious, TransparentIndex: 7},
+	}
+	frames := r.Frames()
+	if len(frames) != len(expected) {
+		t.Fatalf("Expected %d frames; got %d", len(expected), len(frames))
+	}
+	for k, f := range frames {
+		if f != expected[k] {
+			t.Fatalf("Expected frame %+v; got %+v", expected[k], f)
+		}
+	}
+	if d := r.Duration(); d != 2*time.Second {
+		t.Fatalf("Expected duration 2s; got %s", d)
+	}
+
+	if n, ok := r.LoopCount(); !ok || n != 0 {
+		t.Fatalf("Expected to loop forever; got %d, %t", n, ok)
+	}
+	if c := r.Comments(); len(c) != 1 || c[0] != "Made with a test" {
+		t.Fatalf("Expected one comment; got %q", c)
+	}
+	if x := r.XMP(); x == nil {
+		t.Fatalf("Expected XMP packet")
+	} else if n, ok := x.Get("http://ns.adobe.com/xap/1.0/", "CreatorTool"); !ok || n.Value != "Test" {
+		t.Fatalf("Expected CreatorTool 'Test'; got %v", n)
+	}
+}
+
+func Test_MalformedXMP(t *testing.T) {
+	b := []byte("GIF89a")
+	b = append(b, 0x0a, 0, 0x0a, 0, 0, 0, 0)
+	b = append(b, xmpExtension("<x:xmpmeta><rdf:RDF>")...)
+	b = append(b, 0x21, 0xfe)
+	b = append(b, subBlocks([]byte("after"), 255)...)
+	b = append(b, 0x3b)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err != nil {
+		t.Fatalf("Expected malformed XMP to be skipped; got '%s'", err)
+	}
+	r := ir.(*gif.Reader)
+	if r.XMP() != nil {
+		t.Fatalf("Expected no XMP packet")
+	}
+	if c := r.Comments(); len(c) != 1 || c[0] != "after" {
+		t.Fatalf("Expected the comment after the XMP extension; got %q", c)
+	}
+}
+
+func Test_Truncated(t *testing.T) {
+	b := []byte("GIF87a")
+	b = append(b, 0x0a, 0, 0x0a, 0, 0, 0, 0)
+	// The same screen descriptor, with a global color table of 4 entries
+	withTable := []byte("GIF87a")
+	withTable = append(withTable, 0x0a, 0, 0x0a, 0, 0x81, 0, 0)
+
+	var tcs = []struct {
+		name     string
+		b        []byte
+		expected error
+	}{
+		{"no trailer", append(b, image(0, 10)...), nil},
+		{"truncated image data", append(b, image(0, 10)[:30]...), metadata.ErrTruncatedSegment},
+		{"global color table", append(withTable, make([]byte, 12)...), nil},
+		{"truncated global color table", append(withTable, make([]byte, 7)...), metadata.ErrTruncatedSegment},
+		{"truncated local color table", append(b, image(0, 10)[:13]...), metadata.ErrTruncatedSegment},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(tc.b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			_, err = ir.Read()
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error %v; got %v", tc.expected, err)
+			}
+		})
+	}
+}
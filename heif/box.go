@@ -0,0 +1,142 @@
+package heif
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// box is an ISO Base Media File Format box, read into memory
+type box struct {
+	kind string
+	data []byte
+}
+
+// readBoxes splits a byte slice into the boxes it contains
+func readBoxes(b []byte) ([]box, error) {
+	boxes := []box{}
+	for len(b) != 0 {
+		if len(b) < 8 {
+			return boxes, fmt.Errorf("%w: box header is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		size := uint64(binary.BigEndian.Uint32(b))
+		kind := string(b[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			// The box extends to the end of its container
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return boxes, fmt.Errorf("%w: %s box has no large size", common.ErrTruncatedSegment, kind)
+			}
+			size = binary.BigEndian.Uint64(b[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(b)) {
+			return boxes, fmt.Errorf("%w: %s box with size %d", common.ErrTruncatedSegment, kind, size)
+		}
+		boxes = append(boxes, box{kind: kind, data: b[header:size]})
+		b = b[size:]
+	}
+	return boxes, nil
+}
+
+// cursor reads big-endian values from a box.  The first read past the end
+// records an error, and all subsequent reads return zero values.
+type cursor struct {
+	b   []byte
+	err error
+}
+
+func (c *cursor) take(n int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(c.b) {
+		c.err = fmt.Errorf("%w: needed %d bytes; %d remain", common.ErrTruncatedSegment, n, len(c.b))
+		return nil
+	}
+	t := c.b[:n]
+	c.b = c.b[n:]
+	return t
+}
+
+func (c *cursor) u8() uint8 {
+	if t := c.take(1); t != nil {
+		return t[0]
+	}
+	return 0
+}
+
+func (c *cursor) u16() uint16 {
+	if t := c.take(2); t != nil {
+		return binary.BigEndian.Uint16(t)
+	}
+	return 0
+}
+
+func (c *cursor) u32() uint32 {
+	if t := c.take(4); t != nil {
+		return binary.BigEndian.Uint32(t)
+	}
+	return 0
+}
+
+// uint reads an unsigned integer of 0, 2, 4 or 8 bytes, as used by iloc
+func (c *cursor) uint(size int) uint64 {
+	switch size {
+	case 0:
+		return 0
+	case 2:
+		return uint64(c.u16())
+	case 4:
+		return uint64(c.u32())
+	case 8:
+		if t := c.take(8); t != nil {
+			return binary.BigEndian.Uint64(t)
+		}
+		return 0
+	}
+	if c.err == nil {
+		c.err = fmt.Errorf("Unsupported field size %d", size)
+	}
+	return 0
+}
+
+// id reads a 16 bit item ID for version 0 boxes, or a 32 bit one otherwise
+func (c *cursor) id(wide bool) uint32 {
+	if wide {
+		return c.u32()
+	}
+	return uint32(c.u16())
+}
+
+func (c *cursor) fourcc() string {
+	return string(c.take(4))
+}
+
+// str reads a NUL-terminated string; a missing NUL ends the string at the end
+// of the box
+func (c *cursor) str() string {
+	if c.err != nil {
+		return ""
+	}
+	for k, v := range c.b {
+		if v == 0 {
+			s := string(c.b[:k])
+			c.b = c.b[k+1:]
+			return s
+		}
+	}
+	s := string(c.b)
+	c.b = nil
+	return s
+}
+
+// fullBox reads the version and flags of a full box
+func (c *cursor) fullBox() (uint8, uint32) {
+	v := c.u32()
+	return uint8(v >> 24), v & 0x00ffffff
+}
@@ -0,0 +1,324 @@
+package heif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+
+	// Exif items are TIFF structures
+	_ "github.com/object88/go-image-metadata/tiff"
+)
+
+// brands are the ftyp brands of HEIF images, including HEIC and AVIF
+var brands = map[string]bool{
+	"mif1": true,
+	"mif2": true,
+	"msf1": true,
+	"heic": true,
+	"heix": true,
+	"heim": true,
+	"heis": true,
+	"hevc": true,
+	"hevx": true,
+	"hevm": true,
+	"hevs": true,
+	"avif": true,
+	"avis": true,
+}
+
+// maxFtypSize bounds the ftyp box read by CheckHeader
+const maxFtypSize = 4096
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Reader understands a HEIF byte stream, such as HEIC or AVIF
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	majorBrand string
+	brands     []string
+
+	meta    *meta
+	primary *Image
+	xmp     *xmp.Packet
+}
+
+// CheckHeader checks the byte stream to see if it starts with an ftyp box
+// listing a HEIF brand
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 8)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a HEIF
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(b)
+	if string(b[4:8]) != "ftyp" || size < 16 || size > maxFtypSize {
+		return nil, nil
+	}
+	b = make([]byte, size-8)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	h := &Reader{majorBrand: string(b[0:4])}
+	matched := brands[h.majorBrand]
+	for k := 8; k+4 <= len(b); k += 4 {
+		brand := string(b[k : k+4])
+		h.brands = append(h.brands, brand)
+		matched = matched || brands[brand]
+	}
+	if !matched {
+		return nil, nil
+	}
+
+	options.Logger.Debug("Matched HEIF header", "offset", cur, "brand", h.majorBrand)
+	h.r = reader.CreateBigEndianReader(r, cur, options.Logger)
+	h.options = options
+	return h, nil
+}
+
+// Brands returns the major brand, followed by the compatible brands
+func (r *Reader) Brands() (string, []string) {
+	return r.majorBrand, r.brands
+}
+
+// IsAVIF reports whether the brands identify an AVIF image
+func (r *Reader) IsAVIF() bool {
+	if r.majorBrand == "avif" || r.majorBrand == "avis" {
+		return true
+	}
+	for _, b := range r.brands {
+		if b == "avif" || b == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// Items returns the entries of the item information box
+func (r *Reader) Items() []Item {
+	if r.meta == nil {
+		return nil
+	}
+	return r.meta.items
+}
+
+// References returns the entries of the item reference box
+func (r *Reader) References() []Reference {
+	if r.meta == nil {
+		return nil
+	}
+	return r.meta.references
+}
+
+// Primary returns the primary item with its properties, or nil if there is
+// none
+func (r *Reader) Primary() *Image {
+	return r.primary
+}
+
+// XMP returns the packet from the XMP item, or nil if there is none
+func (r *Reader) XMP() *xmp.Packet {
+	return r.xmp
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+
+	// Loop over the top-level boxes, skipping media data
+	end := start
+	for cur := start; cur+8 <= size; {
+		length, err := r.r.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		kind, err := r.r.ReadBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		header, boxSize := int64(8), int64(length)
+		switch length {
+		case 0:
+			boxSize = size - cur
+		case 1:
+			large, err := r.r.ReadUint64()
+			if err != nil {
+				return 0, err
+			}
+			header, boxSize = 16, int64(large)
+		}
+		r.options.Logger.Debug("Read box", "type", string(kind), "size", boxSize, "offset", cur)
+		if boxSize < header || boxSize > size-cur {
+			return 0, fmt.Errorf("%w: %s box at offset %d with size %d", common.ErrTruncatedSegment, kind, cur, boxSize)
+		}
+
+		if string(kind) == "meta" && r.meta == nil {
+			b, err := r.r.ReadBytes(int(boxSize - header))
+			if err != nil {
+				return 0, err
+			}
+			if r.meta, err = readMeta(b); err != nil {
+				return 0, fmt.Errorf("Failed to read meta box: %w", err)
+			}
+		}
+
+		cur += boxSize
+		end = cur
+		if r.meta != nil && r.options.MetadataOnly {
+			// Everything we need is in the meta box, or referenced from it.
+			r.options.Logger.Debug("Stopping after meta box", "offset", cur)
+			break
+		}
+		if err = r.r.SeekTo(cur); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.meta == nil {
+		return 0, fmt.Errorf("%w: no meta box", common.ErrTruncatedSegment)
+	}
+	if err = r.readItems(tree); err != nil {
+		return 0, err
+	}
+	return end - start, nil
+}
+
+// readItems reads the Exif and XMP items, and the primary item's properties
+func (r *Reader) readItems(tree *tags.Tree) error {
+	r.primary = r.readImage(r.meta.primary)
+
+	if item, ok := r.meta.describes(func(i Item) bool { return i.Type == "Exif" }); ok {
+		b, err := r.readItemData(item.ID)
+		if err != nil {
+			return fmt.Errorf("Failed to read Exif item: %w", err)
+		}
+		// The TIFF header follows a 4 byte offset to it
+		if len(b) < 4 {
+			return fmt.Errorf("%w: Exif item is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		offset := uint64(binary.BigEndian.Uint32(b)) + 4
+		if offset > uint64(len(b)) {
+			return fmt.Errorf("%w: Exif item TIFF header offset %d", common.ErrTruncatedSegment, offset-4)
+		}
+		r1, err := metadata.ReadHeader(bytes.NewReader(b[offset:]), metadata.WithOptions(r.options))
+		if err != nil {
+			return fmt.Errorf("Failed to read Exif item: %w", err)
+		}
+		if _, err = r1.ReadPartial(tree); err != nil {
+			return fmt.Errorf("Failed to read Exif item: %w", err)
+		}
+	}
+
+	isXMP := func(i Item) bool { return i.Type == "mime" && i.ContentType == "application/rdf+xml" }
+	if item, ok := r.meta.describes(isXMP); ok {
+		b, err := r.readItemData(item.ID)
+		if err != nil {
+			r.options.Logger.Debug("Ignoring XMP item", "item", item.ID, "err", err)
+			return nil
+		}
+		// A malformed packet costs only this item
+		if r.xmp, err = xmp.Parse(b); err != nil {
+			r.options.Logger.Debug("Ignoring XMP item", "item", item.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// readItemData concatenates the extents of an item, from the file or from
+// the idat box
+func (r *Reader) readItemData(id uint32) ([]byte, error) {
+	l, ok := r.meta.locations[id]
+	if !ok {
+		return nil, fmt.Errorf("Item %d has no location", id)
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check every extent, and the total, before allocating anything.
+	if l.method != 0 && l.method != 1 {
+		return nil, fmt.Errorf("Item %d has unsupported construction method %d", id, l.method)
+	}
+	limit := uint64(size)
+	if l.method == 1 {
+		limit = uint64(len(r.meta.idat))
+	}
+	offsets := make([]uint64, len(l.extents))
+	lengths := make([]uint64, len(l.extents))
+	total := uint64(0)
+	for k, e := range l.extents {
+		offset, carry := bits.Add64(l.baseOffset, e.offset, 0)
+		if carry != 0 || offset > limit {
+			return nil, fmt.Errorf("%w: item %d extent %d at base offset %d and offset %d", common.ErrTruncatedSegment, id, k, l.baseOffset, e.offset)
+		}
+		length := e.length
+		if length == 0 && l.method == 0 {
+			// Only a single extent may run to the end of the file
+			if len(l.extents) != 1 {
+				return nil, fmt.Errorf("%w: item %d extent %d at offset %d has no length", common.ErrTruncatedSegment, id, k, offset)
+			}
+			length = limit - offset
+		}
+		if end, carry := bits.Add64(offset, length, 0); carry != 0 || end > limit {
+			return nil, fmt.Errorf("%w: item %d extent at offset %d with length %d", common.ErrTruncatedSegment, id, offset, length)
+		}
+		offsets[k], lengths[k] = offset, length
+		total += length
+		if total > limit {
+			return nil, fmt.Errorf("%w: item %d is %d bytes, larger than its source", common.ErrTruncatedSegment, id, total)
+		}
+	}
+
+	b := make([]byte, 0, total)
+	for k, offset := range offsets {
+		if l.method == 1 {
+			b = append(b, r.meta.idat[offset:offset+lengths[k]]...)
+			continue
+		}
+		if err = r.r.SeekTo(int64(offset)); err != nil {
+			return nil, err
+		}
+		data, err := r.r.ReadBytes(int(lengths[k]))
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, data...)
+	}
+	return b, nil
+}
@@ -0,0 +1,228 @@
+package heif_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/heif"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+// box encodes a box, with its size and type
+func box(kind string, data ...[]byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, 0)
+	b = append(b, kind...)
+	for _, d := range data {
+		b = append(b, d...)
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+// fullBox encodes a box which starts with a version and flags
+func fullBox(kind string, version uint8, flags uint32, data ...[]byte) []byte {
+	vf := binary.BigEndian.AppendUint32(nil, uint32(version)<<24|flags)
+	return box(kind, append([][]byte{vf}, data...)...)
+}
+
+func ftyp(major string, compatible ...string) []byte {
+	b := append([]byte(major), 0, 0, 0, 0)
+	for _, c := range compatible {
+		b = append(b, c...)
+	}
+	return box("ftyp", b)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// infe encodes a version 2 item information entry
+func infe(id uint16, kind, name, contentType string) []byte {
+	b := append(u16(id), 0, 0)
+	b = append(b, kind...)
+	b = append(b, name...)
+	b = append(b, 0)
+	if kind == "mime" {
+		b = append(b, contentType...)
+		b = append(b, 0)
+	}
+	return fullBox("infe", 2, 0, b)
+}
+
+// buildHeif lays out an image item, an Exif item in the mdat box, and an XMP
+// item in the idat box
+func buildHeif(brand []byte, codec string, colr []byte) []byte {
+	exif := []byte{0, 0, 0, 6, 'E', 'x', 'i', 'f', 0, 0}
+	exif = append(exif, 'M', 'M', 0, 0x2a, 0, 0, 0, 8, 0, 1, 0x01, 0x00, 0, 3, 0, 0, 0, 1, 0x0f, 0xc0, 0, 0, 0, 0, 0, 0)
+	image := []byte{1, 2, 3, 4}
+	packet := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:CreatorTool="Test"/></rdf:RDF></x:xmpmeta>`)
+
+	meta := func(mdat uint32) []byte {
+		iloc := []byte{0x44, 0x00}
+		iloc = append(iloc, u16(3)...)
+		for _, l := range []struct {
+			id     uint16
+			method uint16
+			offset uint32
+			length int
+		}{
+			{1, 0, mdat, len(image)},
+			{2, 0, mdat + uint32(len(image)), len(exif)},
+			{3, 1, 0, len(packet)},
+		} {
+			iloc = append(iloc, u16(l.id)...)
+			iloc = append(iloc, u16(l.method)...)
+			iloc = append(iloc, 0, 0, 0, 1)
+			iloc = append(iloc, u32(l.offset)...)
+			iloc = append(iloc, u32(uint32(l.length))...)
+		}
+
+		ispe := fullBox("ispe", 0, 0, u32(4032), u32(3024))
+		ipma := append(u32(1), 0, 1, 4, 0x81, 0x02, 0x03, 0x84)
+
+		return fullBox("meta", 0, 0,
+			fullBox("hdlr", 0, 0, u32(0), []byte("pict"), make([]byte, 13)),
+			fullBox("pitm", 0, 0, u16(1)),
+			fullBox("iinf", 0, 0, u16(3),
+				infe(1, codec, "", ""),
+				infe(2, "Exif", "", ""),
+				infe(3, "mime", "XMP", "application/rdf+xml")),
+			fullBox("iloc", 1, 0, iloc),
+			fullBox("iref", 0, 0,
+				box("cdsc", u16(2), u16(1), u16(1)),
+				box("cdsc", u16(3), u16(1), u16(1))),
+			box("iprp",
+				box("ipco", ispe, box("irot", []byte{3}), box("imir", []byte{1}), box("colr", colr)),
+				fullBox("ipma", 0, 0, ipma)),
+			box("idat", packet),
+		)
+	}
+
+	start := uint32(len(brand) + len(meta(0)) + 8)
+	b := append(brand, meta(start)...)
+	return append(b, box("mdat", image, exif)...)
+}
+
+func Test_Items(t *testing.T) {
+	nclx := append([]byte("nclx"), 0, 1, 0, 13, 0, 6, 0x80)
+
+	var tcs = []struct {
+		name  string
+		brand []byte
+		codec string
+		avif  bool
+		opts  []metadata.Option
+	}{
+		{"HEIC", ftyp("heic", "mif1", "heic"), "hvc1", false, nil},
+		{"AVIF", ftyp("avif", "mif1", "avif", "miaf"), "av01", true, nil},
+		{"compatible brand", ftyp("isom", "mif1"), "hvc1", false, nil},
+		{"metadata only", ftyp("heic", "mif1"), "hvc1", false, []metadata.Option{metadata.WithMetadataOnly()}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := buildHeif(tc.brand, tc.codec, nclx)
+			ir, err := metadata.ReadHeader(bytes.NewReader(b), tc.opts...)
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if err != nil {
+				t.Fatalf("Error while reading boxes: %s\n", err)
+			}
+			r := ir.(*heif.Reader)
+
+			if r.IsAVIF() != tc.avif {
+				t.Fatalf("Expected IsAVIF %t; got %t", tc.avif, r.IsAVIF())
+			}
+			if items := r.Items(); len(items) != 3 || items[2].ContentType != "application/rdf+xml" {
+				t.Fatalf("Expected 3 items, with XMP last; got %+v", items)
+			}
+			if refs := r.References(); len(refs) != 2 || refs[0].Type != "cdsc" || refs[0].From != 2 {
+				t.Fatalf("Expected 2 cdsc references; got %+v", refs)
+			}
+
+			p := r.Primary()
+			if p == nil || p.Type != tc.codec || p.Width != 4032 || p.Height != 3024 {
+				t.Fatalf("Expected 4032x3024 %s primary item; got %+v", tc.codec, p)
+			}
+			if p.Rotation != 270 || p.Mirror != heif.HorizontalAxis {
+				t.Fatalf("Expected 270 degree rotation and horizontal mirror; got %d and %s", p.Rotation, p.Mirror)
+			}
+			if c := p.Color; c == nil || c.Type != "nclx" || c.Primaries != 1 || c.Transfer != 13 || c.Matrix != 6 || !c.FullRange {
+				t.Fatalf("Expected full range sRGB nclx colour; got %+v", c)
+			}
+
+			if v, ok := tree.Get(tags.Ifd0, 0x0100); !ok || v.Uint32s()[0] != 4032 {
+				t.Fatalf("Expected Exif ImageWidth 4032; got %v", v)
+			}
+			if x := r.XMP(); x == nil {
+				t.Fatalf("Expected XMP packet")
+			} else if n, ok := x.Get("http://ns.adobe.com/xap/1.0/", "CreatorTool"); !ok || n.Value != "Test" {
+				t.Fatalf("Expected CreatorTool 'Test'; got %v", n)
+			}
+		})
+	}
+}
+
+func Test_NotHeif(t *testing.T) {
+	b := append(ftyp("mp42", "isom", "mp41"), box("mdat")...)
+	if _, err := metadata.ReadHeader(bytes.NewReader(b)); err == nil {
+		t.Fatalf("Expected MP4 to be rejected")
+	}
+}
+
+func Test_Extents(t *testing.T) {
+	// With zero-sized offset and length fields, extents take no bytes in the
+	// iloc box, and each one would cover the whole file.
+	empty := []byte{0x00, 0x00}
+	empty = append(empty, u16(1)...)
+	empty = append(empty, u16(1)...)
+	empty = append(empty, u16(0)...)
+	empty = append(empty, u16(0)...)
+	empty = append(empty, u16(0xffff)...)
+
+	// A base offset which wraps around to the start of the file when the
+	// extent offset is added to it
+	wrapped := []byte{0x44, 0x80}
+	wrapped = append(wrapped, u16(1)...)
+	wrapped = append(wrapped, u16(1)...)
+	wrapped = append(wrapped, u16(0)...)
+	wrapped = append(wrapped, u16(0)...)
+	wrapped = binary.BigEndian.AppendUint64(wrapped, 0xffffffffffffff00)
+	wrapped = append(wrapped, u16(1)...)
+	wrapped = append(wrapped, u32(0x200)...)
+	wrapped = append(wrapped, u32(0x10)...)
+
+	var tcs = []struct {
+		name string
+		iloc []byte
+	}{
+		{"empty extents", empty},
+		{"wrapped offset", wrapped},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := ftyp("heic", "mif1")
+			b = append(b, fullBox("meta", 0, 0,
+				fullBox("hdlr", 0, 0, u32(0), []byte("pict"), make([]byte, 13)),
+				fullBox("pitm", 0, 0, u16(1)),
+				fullBox("iinf", 0, 0, u16(1), infe(1, "Exif", "", "")),
+				fullBox("iloc", 1, 0, tc.iloc),
+			)...)
+			b = append(b, box("mdat", make([]byte, 1024))...)
+
+			ir, err := metadata.ReadHeader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			if _, err = ir.Read(); !errors.Is(err, metadata.ErrTruncatedSegment) {
+				t.Fatalf("Expected '%s'; got '%v'", metadata.ErrTruncatedSegment, err)
+			}
+		})
+	}
+}
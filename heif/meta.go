@@ -0,0 +1,282 @@
+package heif
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Item is an entry of the item information box
+type Item struct {
+	ID uint32
+
+	// Type is the item type, such as "hvc1", "av01", "grid", "Exif" or "mime"
+	Type string
+
+	Name string
+
+	// ContentType is the MIME type of a "mime" item
+	ContentType string
+
+	// Hidden is true if the item is not intended to be displayed
+	Hidden bool
+}
+
+// Reference is an entry of the item reference box, such as a "cdsc" link from
+// an Exif item to the image it describes
+type Reference struct {
+	Type string
+	From uint32
+	To   []uint32
+}
+
+type extent struct {
+	offset uint64
+	length uint64
+}
+
+// location is an entry of the item location box
+type location struct {
+	method     uint8
+	baseOffset uint64
+	extents    []extent
+}
+
+type association struct {
+	index     uint16
+	essential bool
+}
+
+// meta holds the boxes of the meta box which describe items
+type meta struct {
+	handler      string
+	primary      uint32
+	items        []Item
+	locations    map[uint32]location
+	references   []Reference
+	properties   []box
+	associations map[uint32][]association
+	idat         []byte
+}
+
+func readMeta(b []byte) (*meta, error) {
+	c := &cursor{b: b}
+	c.fullBox()
+	if c.err != nil {
+		return nil, c.err
+	}
+	children, err := readBoxes(c.b)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &meta{locations: map[uint32]location{}, associations: map[uint32][]association{}}
+	for _, child := range children {
+		c := &cursor{b: child.data}
+		switch child.kind {
+		case "hdlr":
+			c.fullBox()
+			c.u32()
+			m.handler = c.fourcc()
+		case "pitm":
+			version, _ := c.fullBox()
+			m.primary = c.id(version != 0)
+		case "iinf":
+			err = m.readIinf(c)
+		case "iloc":
+			m.readIloc(c)
+		case "iref":
+			err = m.readIref(c)
+		case "iprp":
+			err = m.readIprp(c)
+		case "idat":
+			m.idat = child.data
+		}
+		if err == nil {
+			err = c.err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %s box: %w", child.kind, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *meta) readIinf(c *cursor) error {
+	version, _ := c.fullBox()
+	c.id(version != 0)
+	if c.err != nil {
+		return c.err
+	}
+	entries, err := readBoxes(c.b)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.kind != "infe" {
+			continue
+		}
+		ec := &cursor{b: e.data}
+		version, flags := ec.fullBox()
+		item := Item{Hidden: flags&1 != 0}
+		if version < 2 {
+			item.ID = uint32(ec.u16())
+			ec.u16()
+			item.Name = ec.str()
+			item.ContentType = ec.str()
+		} else {
+			item.ID = ec.id(version != 2)
+			ec.u16()
+			item.Type = ec.fourcc()
+			item.Name = ec.str()
+			if item.Type == "mime" {
+				item.ContentType = ec.str()
+			}
+		}
+		if ec.err != nil {
+			return ec.err
+		}
+		m.items = append(m.items, item)
+	}
+	return nil
+}
+
+func (m *meta) readIloc(c *cursor) {
+	version, _ := c.fullBox()
+	sizes := c.u8()
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0f)
+	sizes = c.u8()
+	baseOffsetSize, indexSize := int(sizes>>4), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0f)
+	}
+	count := c.id(version == 2)
+
+	for k := uint32(0); k < count && c.err == nil; k++ {
+		id := c.id(version == 2)
+		l := location{}
+		if version == 1 || version == 2 {
+			l.method = uint8(c.u16() & 0x0f)
+		}
+		c.u16()
+		l.baseOffset = c.uint(baseOffsetSize)
+		extents := c.u16()
+		if c.err == nil && !extentsFit(int(extents), indexSize+offsetSize+lengthSize, len(c.b)) {
+			c.err = fmt.Errorf("%w: item %d has %d extents in %d bytes", common.ErrTruncatedSegment, id, extents, len(c.b))
+		}
+		for e := uint16(0); e < extents && c.err == nil; e++ {
+			c.uint(indexSize)
+			l.extents = append(l.extents, extent{offset: c.uint(offsetSize), length: c.uint(lengthSize)})
+		}
+		m.locations[id] = l
+	}
+}
+
+// extentsFit reports whether count extents of the provided size can be read
+// from the bytes remaining in the box.  Extents which take no bytes can only
+// describe a single extent covering the whole file.
+func extentsFit(count, size, remaining int) bool {
+	if size == 0 {
+		return count <= 1
+	}
+	return count*size <= remaining
+}
+
+func (m *meta) readIref(c *cursor) error {
+	version, _ := c.fullBox()
+	if c.err != nil {
+		return c.err
+	}
+	refs, err := readBoxes(c.b)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		rc := &cursor{b: ref.data}
+		r := Reference{Type: ref.kind, From: rc.id(version != 0)}
+		count := rc.u16()
+		for k := uint16(0); k < count && rc.err == nil; k++ {
+			r.To = append(r.To, rc.id(version != 0))
+		}
+		if rc.err != nil {
+			return rc.err
+		}
+		m.references = append(m.references, r)
+	}
+	return nil
+}
+
+func (m *meta) readIprp(c *cursor) error {
+	children, err := readBoxes(c.b)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		switch child.kind {
+		case "ipco":
+			if m.properties, err = readBoxes(child.data); err != nil {
+				return err
+			}
+		case "ipma":
+			ac := &cursor{b: child.data}
+			version, flags := ac.fullBox()
+			count := ac.u32()
+			for k := uint32(0); k < count && ac.err == nil; k++ {
+				id := ac.id(version >= 1)
+				n := ac.u8()
+				for a := uint8(0); a < n && ac.err == nil; a++ {
+					if flags&1 != 0 {
+						v := ac.u16()
+						m.associations[id] = append(m.associations[id], association{index: v & 0x7fff, essential: v&0x8000 != 0})
+					} else {
+						v := ac.u8()
+						m.associations[id] = append(m.associations[id], association{index: uint16(v & 0x7f), essential: v&0x80 != 0})
+					}
+				}
+			}
+			if ac.err != nil {
+				return ac.err
+			}
+		}
+	}
+	return nil
+}
+
+// itemProperties returns the property boxes associated with an item, in
+// association order.  Indexes are 1-based; 0 means no property.
+func (m *meta) itemProperties(id uint32) []box {
+	props := []box{}
+	for _, a := range m.associations[id] {
+		if a.index == 0 || int(a.index) > len(m.properties) {
+			continue
+		}
+		props = append(props, m.properties[a.index-1])
+	}
+	return props
+}
+
+// describes returns the first item of the provided type which has a "cdsc"
+// reference to the primary item, or else the first item of that type
+func (m *meta) describes(match func(Item) bool) (Item, bool) {
+	for _, r := range m.references {
+		if r.Type != "cdsc" {
+			continue
+		}
+		for _, to := range r.To {
+			if to != m.primary {
+				continue
+			}
+			for _, item := range m.items {
+				if item.ID == r.From && match(item) {
+					return item, true
+				}
+			}
+		}
+	}
+	for _, item := range m.items {
+		if match(item) {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
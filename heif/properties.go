@@ -0,0 +1,124 @@
+package heif
+
+import (
+	"github.com/object88/go-image-metadata/icc"
+)
+
+// MirrorAxis is the axis of an imir property
+type MirrorAxis int
+
+const (
+	// NoMirror means the image is not mirrored
+	NoMirror MirrorAxis = iota
+
+	// VerticalAxis mirrors the image about a vertical axis, swapping left and
+	// right
+	VerticalAxis
+
+	// HorizontalAxis mirrors the image about a horizontal axis, swapping top and
+	// bottom
+	HorizontalAxis
+)
+
+var mirrorAxisNames = [...]string{"none", "vertical", "horizontal"}
+
+func (m MirrorAxis) String() string {
+	if m < 0 || int(m) >= len(mirrorAxisNames) {
+		return "unknown"
+	}
+	return mirrorAxisNames[m]
+}
+
+// Color holds a colr property, which is either nclx coding-independent code
+// points or an ICC profile
+type Color struct {
+	// Type is "nclx", "rICC" or "prof"
+	Type string
+
+	Primaries uint16
+	Transfer  uint16
+	Matrix    uint16
+	FullRange bool
+
+	Profile *icc.Profile
+}
+
+// Image is an item with its spatial and colour properties
+type Image struct {
+	Item
+
+	Width  uint32
+	Height uint32
+
+	// Rotation is the anti-clockwise rotation in degrees: 0, 90, 180 or 270
+	Rotation int
+
+	// Mirror is applied after Rotation
+	Mirror MirrorAxis
+
+	Color *Color
+}
+
+// readImage collects the properties of an item, or returns nil if the item
+// does not exist
+func (r *Reader) readImage(id uint32) *Image {
+	var img *Image
+	for _, item := range r.meta.items {
+		if item.ID == id {
+			img = &Image{Item: item}
+			break
+		}
+	}
+	if img == nil {
+		return nil
+	}
+
+	for _, p := range r.meta.itemProperties(id) {
+		c := &cursor{b: p.data}
+		switch p.kind {
+		case "ispe":
+			c.fullBox()
+			width, height := c.u32(), c.u32()
+			if c.err == nil {
+				img.Width, img.Height = width, height
+			}
+		case "irot":
+			img.Rotation = int(c.u8()&0x03) * 90
+		case "imir":
+			if v := c.u8(); c.err == nil {
+				img.Mirror = VerticalAxis + MirrorAxis(v&0x01)
+			}
+		case "colr":
+			if img.Color == nil {
+				img.Color = r.readColor(c)
+			}
+		}
+		if c.err != nil {
+			r.options.Logger.Debug("Failed to read property", "type", p.kind, "item", id, "err", c.err)
+		}
+	}
+	return img
+}
+
+func (r *Reader) readColor(c *cursor) *Color {
+	color := &Color{Type: c.fourcc()}
+	switch color.Type {
+	case "nclx":
+		color.Primaries, color.Transfer, color.Matrix = c.u16(), c.u16(), c.u16()
+		color.FullRange = c.u8()&0x80 != 0
+	case "rICC", "prof":
+		if c.err != nil {
+			return nil
+		}
+		p, err := icc.Parse(c.b)
+		if err != nil {
+			r.options.Logger.Debug("Failed to read ICC profile", "err", err)
+			return color
+		}
+		color.Profile = p
+	}
+	if c.err != nil {
+		return nil
+	}
+	return color
+}
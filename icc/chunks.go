@@ -0,0 +1,55 @@
+package icc
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Chunks collects a profile split across several segments, such as JPEG APP2
+// ICC_PROFILE segments.  Each chunk starts with its 1-based sequence number
+// and the total number of chunks.
+type Chunks struct {
+	count  uint8
+	chunks map[uint8][]byte
+}
+
+// AddChunk records one chunk, starting with its sequence number and count
+func (c *Chunks) AddChunk(b []byte) error {
+	if len(b) < 2 {
+		return fmt.Errorf("%w: ICC profile chunk is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	seq, count := b[0], b[1]
+	if seq == 0 || seq > count {
+		return fmt.Errorf("ICC profile chunk %d of %d is out of range", seq, count)
+	}
+	if c.chunks == nil {
+		c.count = count
+		c.chunks = map[uint8][]byte{}
+	} else if count != c.count {
+		return fmt.Errorf("ICC profile chunk %d has count %d; expected %d", seq, count, c.count)
+	}
+	if _, ok := c.chunks[seq]; ok {
+		return fmt.Errorf("Duplicate ICC profile chunk %d", seq)
+	}
+	c.chunks[seq] = b[2:]
+	return nil
+}
+
+// Empty reports whether no chunks have been added
+func (c *Chunks) Empty() bool {
+	return len(c.chunks) == 0
+}
+
+// Assemble returns the profile, if every chunk is present
+func (c *Chunks) Assemble() ([]byte, error) {
+	b := []byte{}
+	for seq := 1; seq <= int(c.count); seq++ {
+		chunk, ok := c.chunks[uint8(seq)]
+		if !ok {
+			return nil, fmt.Errorf("Missing ICC profile chunk %d of %d", seq, c.count)
+		}
+		b = append(b, chunk...)
+	}
+	return b, nil
+}
@@ -0,0 +1,238 @@
+package icc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+const headerSize = 128
+
+// Class is the profile/device class of an ICC profile
+type Class int
+
+const (
+	// UnknownClass is any unrecognized class signature
+	UnknownClass Class = iota
+
+	// InputClass is an input device profile, such as a scanner or camera
+	InputClass
+
+	// DisplayClass is a display device profile
+	DisplayClass
+
+	// OutputClass is an output device profile, such as a printer
+	OutputClass
+
+	// DeviceLinkClass is a device link profile
+	DeviceLinkClass
+
+	// ColorSpaceClass is a color space conversion profile
+	ColorSpaceClass
+
+	// AbstractClass is an abstract profile
+	AbstractClass
+
+	// NamedColorClass is a named color profile
+	NamedColorClass
+)
+
+var classes = [...]string{
+	"unknown",
+	"input",
+	"display",
+	"output",
+	"device link",
+	"color space",
+	"abstract",
+	"named color",
+}
+
+var classSignatures = map[string]Class{
+	"scnr": InputClass,
+	"mntr": DisplayClass,
+	"prtr": OutputClass,
+	"link": DeviceLinkClass,
+	"spac": ColorSpaceClass,
+	"abst": AbstractClass,
+	"nmcl": NamedColorClass,
+}
+
+func (c Class) String() string {
+	return classes[c]
+}
+
+// RenderingIntent is the rendering intent in the profile header
+type RenderingIntent uint32
+
+const (
+	// Perceptual rendering intent
+	Perceptual RenderingIntent = iota
+
+	// RelativeColorimetric rendering intent
+	RelativeColorimetric
+
+	// Saturation rendering intent
+	Saturation
+
+	// AbsoluteColorimetric rendering intent
+	AbsoluteColorimetric
+)
+
+var renderingIntents = [...]string{
+	"perceptual",
+	"relative colorimetric",
+	"saturation",
+	"absolute colorimetric",
+}
+
+func (i RenderingIntent) String() string {
+	if int(i) >= len(renderingIntents) {
+		return "unknown"
+	}
+	return renderingIntents[i]
+}
+
+// Version is the version of the ICC specification a profile conforms to
+type Version struct {
+	Major  uint8
+	Minor  uint8
+	Bugfix uint8
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Bugfix)
+}
+
+// XYZ is a CIE XYZ color
+type XYZ struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Profile is the header view of an ICC profile
+type Profile struct {
+	// Raw holds the bytes of the whole profile
+	Raw []byte
+
+	Version         Version
+	Class           Class
+	RenderingIntent RenderingIntent
+
+	// ColorSpace is the data color space signature, such as "RGB" or "CMYK",
+	// with trailing spaces removed
+	ColorSpace string
+
+	// PCS is the profile connection space signature, "XYZ" or "Lab"
+	PCS string
+
+	// Description is the text of the 'desc' tag, such as "Display P3"
+	Description string
+
+	// WhitePoint is the media white point from the 'wtpt' tag, or nil if
+	// the profile has none
+	WhitePoint *XYZ
+}
+
+// Parse parses the header and tag table of an ICC profile
+func Parse(b []byte) (*Profile, error) {
+	if len(b) < headerSize+4 {
+		return nil, fmt.Errorf("%w: ICC profile is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	if string(b[36:40]) != "acsp" {
+		return nil, fmt.Errorf("ICC profile has no 'acsp' signature")
+	}
+
+	p := &Profile{
+		Raw: b,
+		Version: Version{
+			Major:  b[8],
+			Minor:  b[9] >> 4,
+			Bugfix: b[9] & 0x0f,
+		},
+		Class:           classSignatures[string(b[12:16])],
+		ColorSpace:      strings.TrimRight(string(b[16:20]), " "),
+		PCS:             strings.TrimRight(string(b[20:24]), " "),
+		RenderingIntent: RenderingIntent(binary.BigEndian.Uint32(b[64:68]) & 0xffff),
+	}
+
+	count := binary.BigEndian.Uint32(b[headerSize:])
+	if uint64(count)*12 > uint64(len(b)-headerSize-4) {
+		return nil, fmt.Errorf("%w: ICC tag table with %d entries", common.ErrTruncatedSegment, count)
+	}
+	for k := uint32(0); k < count; k++ {
+		entry := b[headerSize+4+k*12:]
+		signature := string(entry[0:4])
+		offset := binary.BigEndian.Uint32(entry[4:8])
+		size := binary.BigEndian.Uint32(entry[8:12])
+		if uint64(offset)+uint64(size) > uint64(len(b)) {
+			return nil, fmt.Errorf("%w: ICC tag '%s' at offset %d with size %d", common.ErrTruncatedSegment, signature, offset, size)
+		}
+		data := b[offset : offset+size]
+
+		switch signature {
+		case "desc":
+			p.Description = readText(data)
+		case "wtpt":
+			p.WhitePoint = readXYZ(data)
+		}
+	}
+
+	return p, nil
+}
+
+// readText reads the v2 textDescriptionType or the v4
+// multiLocalizedUnicodeType, returning the first (usually en-US) record of
+// the latter
+func readText(data []byte) string {
+	if len(data) < 12 {
+		return ""
+	}
+	switch string(data[0:4]) {
+	case "desc":
+		n := binary.BigEndian.Uint32(data[8:12])
+		if uint64(n) > uint64(len(data)-12) {
+			return ""
+		}
+		return string(bytes.TrimRight(data[12:12+n], "\x00"))
+	case "mluc":
+		records := binary.BigEndian.Uint32(data[8:12])
+		if records == 0 || len(data) < 28 {
+			return ""
+		}
+		n := binary.BigEndian.Uint32(data[20:24])
+		offset := binary.BigEndian.Uint32(data[24:28])
+		if uint64(offset)+uint64(n) > uint64(len(data)) {
+			return ""
+		}
+		s := data[offset : offset+n]
+		u := make([]uint16, len(s)/2)
+		for k := range u {
+			u[k] = binary.BigEndian.Uint16(s[k*2:])
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	case "text":
+		return string(bytes.TrimRight(data[8:], "\x00"))
+	}
+	return ""
+}
+
+func readXYZ(data []byte) *XYZ {
+	if len(data) < 20 || string(data[0:4]) != "XYZ " {
+		return nil
+	}
+	return &XYZ{
+		X: s15Fixed16(data[8:12]),
+		Y: s15Fixed16(data[12:16]),
+		Z: s15Fixed16(data[16:20]),
+	}
+}
+
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
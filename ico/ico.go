@@ -0,0 +1,264 @@
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/bmp"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// entrySize is the size of a directory entry
+const entrySize = 16
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Type distinguishes icons from cursors
+type Type int
+
+const (
+	// Icon is an ICO file
+	Icon Type = 1
+
+	// Cursor is a CUR file
+	Cursor Type = 2
+)
+
+func (t Type) String() string {
+	switch t {
+	case Icon:
+		return "icon"
+	case Cursor:
+		return "cursor"
+	}
+	return "unknown"
+}
+
+// Format is the encoding of an entry's image
+type Format int
+
+const (
+	// DIB images are a DIB header and pixel data, without a BMP file header
+	DIB Format = iota
+
+	// PNG images are complete PNG files
+	PNG
+)
+
+var formats = [...]string{
+	"DIB",
+	"PNG",
+}
+
+func (f Format) String() string {
+	return formats[f]
+}
+
+// Entry is a directory entry, with the format of its image
+type Entry struct {
+	// Width and Height are in pixels, where the directory's 0 means 256
+	Width  int
+	Height int
+
+	// Colors is the number of palette entries, or 0 if there is no palette
+	Colors int
+
+	// Planes is only set for icons.  BitCount is taken from the image for
+	// cursors, and for icons whose directory entry leaves it as 0.
+	Planes   uint16
+	BitCount uint16
+
+	// HotspotX and HotspotY are only set for cursors
+	HotspotX uint16
+	HotspotY uint16
+
+	// Size and Offset locate the image in the file
+	Size   uint32
+	Offset uint32
+
+	Format Format
+
+	// DIB is the header of a DIB image, or nil for a PNG image.  Its height
+	// covers both the color and the mask bitmaps.
+	DIB *bmp.DIBHeader
+}
+
+// Reader understands an ICO or CUR byte stream
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	kind    Type
+	count   uint16
+	entries []Entry
+}
+
+// CheckHeader checks the byte stream to see if it starts with an icon or
+// cursor directory.  The 6 byte directory header is common at the start of
+// other files, so the first entry must also be plausible: its reserved byte
+// is 0, and its image lies after the directory, inside the stream.
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 6+entrySize)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be an ICO
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	le := binary.LittleEndian
+	kind := Type(le.Uint16(b[2:]))
+	count := le.Uint16(b[4:])
+	if b[0] != 0 || b[1] != 0 || (kind != Icon && kind != Cursor) || count == 0 {
+		return nil, nil
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	size, offset := int64(le.Uint32(b[6+8:])), int64(le.Uint32(b[6+12:]))
+	if b[6+3] != 0 || size == 0 || offset < 6+int64(count)*entrySize || cur+offset+size > end {
+		return nil, nil
+	}
+	if _, err = r.Seek(cur+6, io.SeekStart); err != nil {
+		return nil, err
+	}
+	options.Logger.Debug("Matched ICO header", "offset", cur, "type", kind, "count", count)
+	return &Reader{r: reader.CreateLittleEndianReader(r, cur, options.Logger), options: options, kind: kind, count: count}, nil
+}
+
+// Type returns whether this is an icon or a cursor
+func (r *Reader) Type() Type {
+	return r.kind
+}
+
+// Entries returns the directory entries, in directory order
+func (r *Reader) Entries() []Entry {
+	return r.entries
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+
+	if start+int64(r.count)*entrySize > size {
+		return 0, fmt.Errorf("%w: directory of %d entries", common.ErrTruncatedSegment, r.count)
+	}
+	dir, err := r.r.ReadBytes(int(r.count) * entrySize)
+	if err != nil {
+		return 0, err
+	}
+	end, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+
+	for k := 0; k < int(r.count); k++ {
+		e := r.readEntry(dir[k*entrySize : (k+1)*entrySize])
+		if int64(e.Offset)+int64(e.Size) > size {
+			return 0, fmt.Errorf("%w: entry %d at offset %d with size %d", common.ErrTruncatedSegment, k, e.Offset, e.Size)
+		}
+		if err = r.readImageHeader(&e); err != nil {
+			return 0, fmt.Errorf("Failed to read entry %d: %w", k, err)
+		}
+		r.options.Logger.Debug("Read entry", "index", k, "format", e.Format, "width", e.Width, "height", e.Height, "bitcount", e.BitCount)
+		r.entries = append(r.entries, e)
+	}
+
+	return end - start, nil
+}
+
+func (r *Reader) readEntry(b []byte) Entry {
+	order := r.r.GetByteOrder()
+	e := Entry{
+		Width:  int(b[0]),
+		Height: int(b[1]),
+		Colors: int(b[2]),
+		Size:   order.Uint32(b[8:]),
+		Offset: order.Uint32(b[12:]),
+	}
+	if e.Width == 0 {
+		e.Width = 256
+	}
+	if e.Height == 0 {
+		e.Height = 256
+	}
+	if r.kind == Cursor {
+		e.HotspotX = order.Uint16(b[4:])
+		e.HotspotY = order.Uint16(b[6:])
+	} else {
+		e.Planes = order.Uint16(b[4:])
+		e.BitCount = order.Uint16(b[6:])
+	}
+	return e
+}
+
+// readImageHeader identifies the format of an entry's image, and fills in
+// the bit count if the directory does not have it
+func (r *Reader) readImageHeader(e *Entry) error {
+	if err := r.r.SeekTo(int64(e.Offset)); err != nil {
+		return err
+	}
+	b, err := r.r.ReadBytes(int(min(e.Size, 124)))
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(b, pngSignature) {
+		e.Format = PNG
+		// The IHDR chunk comes first, with the bit depth and color type after
+		// the dimensions.
+		if len(b) >= 26 && e.BitCount == 0 {
+			e.BitCount = uint16(b[24]) * channels(b[25])
+		}
+		return nil
+	}
+
+	e.Format = DIB
+	if e.DIB, err = bmp.ParseDIBHeader(b); err != nil {
+		return err
+	}
+	if e.BitCount == 0 {
+		e.BitCount = e.DIB.BitCount
+	}
+	return nil
+}
+
+// channels returns the number of samples per pixel for a PNG color type
+func channels(colorType uint8) uint16 {
+	switch colorType {
+	case 2:
+		return 3
+	case 4:
+		return 2
+	case 6:
+		return 4
+	}
+	return 1
+}
@@ -0,0 +1,169 @@
+package ico_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/bmp"
+	"github.com/object88/go-image-metadata/ico"
+)
+
+// dib encodes a BITMAPINFOHEADER for an icon image, whose height covers the
+// color and mask bitmaps
+func dib(width, height int32, bitCount uint16) []byte {
+	le := binary.LittleEndian
+	b := make([]byte, 40)
+	le.PutUint32(b, 40)
+	le.PutUint32(b[4:], uint32(width))
+	le.PutUint32(b[8:], uint32(2*height))
+	le.PutUint16(b[12:], 1)
+	le.PutUint16(b[14:], bitCount)
+	return append(b, make([]byte, 16)...)
+}
+
+// png encodes the start of a PNG, up to the IHDR chunk
+func png(width, height uint32, bitDepth, colorType uint8) []byte {
+	b := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 13, 'I', 'H', 'D', 'R'}
+	b = binary.BigEndian.AppendUint32(b, width)
+	b = binary.BigEndian.AppendUint32(b, height)
+	return append(b, bitDepth, colorType, 0, 0, 0, 0, 0, 0, 0)
+}
+
+type image struct {
+	width, height uint8
+	field1        uint16
+	field2        uint16
+	data          []byte
+}
+
+// buildIco lays out the directory, followed by the images
+func buildIco(kind ico.Type, images ...image) []byte {
+	le := binary.LittleEndian
+	b := []byte{0, 0}
+	b = le.AppendUint16(b, uint16(kind))
+	b = le.AppendUint16(b, uint16(len(images)))
+	offset := uint32(6 + 16*len(images))
+	for _, img := range images {
+		b = append(b, img.width, img.height, 0, 0)
+		b = le.AppendUint16(b, img.field1)
+		b = le.AppendUint16(b, img.field2)
+		b = le.AppendUint32(b, uint32(len(img.data)))
+		b = le.AppendUint32(b, offset)
+		offset += uint32(len(img.data))
+	}
+	for _, img := range images {
+		b = append(b, img.data...)
+	}
+	return b
+}
+
+func Test_Entries(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		kind     ico.Type
+		images   []image
+		expected []ico.Entry
+	}{
+		{
+			"icon",
+			ico.Icon,
+			[]image{
+				{16, 16, 1, 32, dib(16, 16, 32)},
+				{0, 0, 1, 0, png(256, 256, 8, 6)},
+			},
+			[]ico.Entry{
+				{Width: 16, Height: 16, Planes: 1, BitCount: 32, Format: ico.DIB},
+				{Width: 256, Height: 256, Planes: 1, BitCount: 32, Format: ico.PNG},
+			},
+		},
+		{
+			"cursor",
+			ico.Cursor,
+			[]image{
+				{32, 32, 3, 5, dib(32, 32, 4)},
+			},
+			[]ico.Entry{
+				{Width: 32, Height: 32, BitCount: 4, HotspotX: 3, HotspotY: 5, Format: ico.DIB},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(buildIco(tc.kind, tc.images...)))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			if _, err = ir.Read(); err != nil {
+				t.Fatalf("Error while reading directory: %s\n", err)
+			}
+			r := ir.(*ico.Reader)
+			if r.Type() != tc.kind {
+				t.Fatalf("Expected %s; got %s", tc.kind, r.Type())
+			}
+
+			entries := r.Entries()
+			if len(entries) != len(tc.expected) {
+				t.Fatalf("Expected %d entries; got %d", len(tc.expected), len(entries))
+			}
+			for k, e := range entries {
+				x := tc.expected[k]
+				if e.Width != x.Width || e.Height != x.Height || e.Planes != x.Planes || e.BitCount != x.BitCount ||
+					e.HotspotX != x.HotspotX || e.HotspotY != x.HotspotY || e.Format != x.Format {
+					t.Fatalf("Expected entry %+v; got %+v", x, e)
+				}
+				if e.Size != uint32(len(tc.images[k].data)) {
+					t.Fatalf("Expected size %d; got %d", len(tc.images[k].data), e.Size)
+				}
+				if (e.Format == ico.DIB) != (e.DIB != nil) {
+					t.Fatalf("Expected DIB header only for DIB images; got %+v", e.DIB)
+				}
+				if e.DIB != nil && e.DIB.Version != bmp.InfoHeader {
+					t.Fatalf("Expected BITMAPINFOHEADER; got %s", e.DIB.Version)
+				}
+			}
+		})
+	}
+}
+
+func Test_Truncated(t *testing.T) {
+	b := buildIco(ico.Icon, image{16, 16, 1, 32, dib(16, 16, 32)}, image{32, 32, 1, 32, dib(32, 32, 32)})
+	ir, err := metadata.ReadHeader(bytes.NewReader(b[:len(b)-1]))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err == nil {
+		t.Fatalf("Expected error for truncated image")
+	}
+}
+
+func Test_NotIco(t *testing.T) {
+	valid := buildIco(ico.Icon, image{16, 16, 1, 32, dib(16, 16, 32)})
+	patch := func(k int, v ...byte) []byte {
+		b := append([]byte{}, valid...)
+		copy(b[k:], v)
+		return b
+	}
+
+	var tcs = []struct {
+		name string
+		b    []byte
+	}{
+		{"no entries", patch(4, 0, 0)},
+		{"reserved byte", patch(6+3, 1)},
+		{"empty image", patch(6+8, 0, 0, 0, 0)},
+		{"image inside directory", patch(6+12, 6, 0, 0, 0)},
+		{"image past the end", valid[:len(valid)-1]},
+		{"directory header only", valid[:6]},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if ir, err := metadata.ReadHeader(bytes.NewReader(tc.b)); err != metadata.ErrUnknownFormat {
+				t.Fatalf("Expected ErrUnknownFormat; got %T, %v", ir, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package iptc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+const (
+	tagMarker = 0x1c
+
+	envelopeRecord    = 1
+	applicationRecord = 2
+)
+
+var utf8Escape = []byte{0x1b, 0x25, 0x47}
+
+type dataSetKey struct {
+	record uint8
+	number uint8
+}
+
+// dataSetNames names the datasets of the envelope (1) and application (2)
+// records
+var dataSetNames = map[dataSetKey]string{
+	{1, 0}:   "ModelVersion",
+	{1, 5}:   "Destination",
+	{1, 20}:  "FileFormat",
+	{1, 22}:  "FileFormatVersion",
+	{1, 30}:  "ServiceIdentifier",
+	{1, 40}:  "EnvelopeNumber",
+	{1, 50}:  "ProductID",
+	{1, 60}:  "EnvelopePriority",
+	{1, 70}:  "DateSent",
+	{1, 80}:  "TimeSent",
+	{1, 90}:  "CodedCharacterSet",
+	{1, 100}: "UniqueNameOfObject",
+	{1, 120}: "ARMIdentifier",
+	{1, 122}: "ARMVersion",
+	{2, 0}:   "RecordVersion",
+	{2, 3}:   "ObjectTypeReference",
+	{2, 4}:   "ObjectAttributeReference",
+	{2, 5}:   "ObjectName",
+	{2, 7}:   "EditStatus",
+	{2, 8}:   "EditorialUpdate",
+	{2, 10}:  "Urgency",
+	{2, 12}:  "SubjectReference",
+	{2, 15}:  "Category",
+	{2, 20}:  "SupplementalCategories",
+	{2, 22}:  "FixtureIdentifier",
+	{2, 25}:  "Keywords",
+	{2, 26}:  "ContentLocationCode",
+	{2, 27}:  "ContentLocationName",
+	{2, 30}:  "ReleaseDate",
+	{2, 35}:  "ReleaseTime",
+	{2, 37}:  "ExpirationDate",
+	{2, 38}:  "ExpirationTime",
+	{2, 40}:  "SpecialInstructions",
+	{2, 42}:  "ActionAdvised",
+	{2, 45}:  "ReferenceService",
+	{2, 47}:  "ReferenceDate",
+	{2, 50}:  "ReferenceNumber",
+	{2, 55}:  "DateCreated",
+	{2, 60}:  "TimeCreated",
+	{2, 62}:  "DigitalCreationDate",
+	{2, 63}:  "DigitalCreationTime",
+	{2, 65}:  "OriginatingProgram",
+	{2, 70}:  "ProgramVersion",
+	{2, 75}:  "ObjectCycle",
+	{2, 80}:  "By-line",
+	{2, 85}:  "By-lineTitle",
+	{2, 90}:  "City",
+	{2, 92}:  "Sub-location",
+	{2, 95}:  "Province-State",
+	{2, 100}: "Country-PrimaryLocationCode",
+	{2, 101}: "Country-PrimaryLocationName",
+	{2, 103}: "OriginalTransmissionReference",
+	{2, 105}: "Headline",
+	{2, 110}: "Credit",
+	{2, 115}: "Source",
+	{2, 116}: "CopyrightNotice",
+	{2, 118}: "Contact",
+	{2, 120}: "Caption-Abstract",
+	{2, 121}: "LocalCaption",
+	{2, 122}: "Writer-Editor",
+	{2, 130}: "ImageType",
+	{2, 131}: "ImageOrientation",
+	{2, 135}: "LanguageIdentifier",
+}
+
+// binaryDataSets are stored as big-endian integers, rather than text
+var binaryDataSets = map[dataSetKey]bool{
+	{1, 0}:  true,
+	{1, 20}: true,
+	{1, 22}: true,
+	{2, 0}:  true,
+}
+
+// DataSet is a single IPTC-IIM dataset
+type DataSet struct {
+	Record uint8
+	Number uint8
+
+	// Name is the name of the dataset, or an empty string if it is not known
+	Name string
+
+	// Value is the decoded text, or the decimal value of a binary dataset
+	Value string
+
+	// Raw holds the undecoded bytes
+	Raw []byte
+}
+
+// IPTC holds the datasets of the envelope and application records, in
+// stream order
+type IPTC struct {
+	DataSets []DataSet
+
+	// UTF8 is true if the CodedCharacterSet dataset selects UTF-8; otherwise
+	// text is decoded as Latin-1
+	UTF8 bool
+}
+
+// Get returns the values of every dataset with the provided name.  Datasets
+// such as Keywords are repeatable, so may have several values.
+func (i *IPTC) Get(name string) []string {
+	values := []string{}
+	for _, d := range i.DataSets {
+		if d.Name == name {
+			values = append(values, d.Value)
+		}
+	}
+	return values
+}
+
+// Fields returns the values of every named dataset, keyed by name
+func (i *IPTC) Fields() map[string][]string {
+	fields := map[string][]string{}
+	for _, d := range i.DataSets {
+		if d.Name != "" {
+			fields[d.Name] = append(fields[d.Name], d.Value)
+		}
+	}
+	return fields
+}
+
+// Parse decodes a stream of IPTC-IIM datasets, keeping those from the
+// envelope and application records
+func Parse(b []byte) (*IPTC, error) {
+	i := &IPTC{}
+	for len(b) != 0 {
+		if b[0] != tagMarker {
+			// Some writers pad the resource with NULs
+			if len(bytes.Trim(b, "\x00")) == 0 {
+				break
+			}
+			return nil, fmt.Errorf("IPTC dataset has tag marker 0x%02x", b[0])
+		}
+		if len(b) < 5 {
+			return nil, fmt.Errorf("%w: IPTC dataset header is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		key := dataSetKey{record: b[1], number: b[2]}
+		size := uint64(binary.BigEndian.Uint16(b[3:5]))
+		b = b[5:]
+
+		if size&0x8000 != 0 {
+			// Extended dataset; the low bits are the size of the size.
+			n := int(size & 0x7fff)
+			if n > 8 || n > len(b) {
+				return nil, fmt.Errorf("%w: IPTC %d:%d extended size of %d bytes", common.ErrTruncatedSegment, key.record, key.number, n)
+			}
+			size = 0
+			for _, c := range b[:n] {
+				size = size<<8 | uint64(c)
+			}
+			b = b[n:]
+		}
+		if size > uint64(len(b)) {
+			return nil, fmt.Errorf("%w: IPTC %d:%d with size %d", common.ErrTruncatedSegment, key.record, key.number, size)
+		}
+		raw := b[:size]
+		b = b[size:]
+
+		if key == (dataSetKey{envelopeRecord, 90}) {
+			i.UTF8 = bytes.Contains(raw, utf8Escape)
+		}
+		if key.record != envelopeRecord && key.record != applicationRecord {
+			continue
+		}
+		i.DataSets = append(i.DataSets, DataSet{Record: key.record, Number: key.number, Name: dataSetNames[key], Raw: raw})
+	}
+
+	// The character set is declared in the envelope record, so decode once
+	// every dataset has been read.
+	for k := range i.DataSets {
+		i.DataSets[k].Value = i.decode(&i.DataSets[k])
+	}
+	return i, nil
+}
+
+func (i *IPTC) decode(d *DataSet) string {
+	if binaryDataSets[dataSetKey{d.Record, d.Number}] {
+		var v uint64
+		for _, c := range d.Raw {
+			v = v<<8 | uint64(c)
+		}
+		return fmt.Sprintf("%d", v)
+	}
+	if d.Record == envelopeRecord && d.Number == 90 {
+		return fmt.Sprintf("%q", d.Raw)
+	}
+	if i.UTF8 && utf8.Valid(d.Raw) {
+		return string(d.Raw)
+	}
+
+	return common.DecodeLatin1(d.Raw)
+}
@@ -0,0 +1,59 @@
+package iptc
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// IPTCResource is the ID of the Photoshop image resource holding IPTC-IIM
+// datasets
+const IPTCResource uint16 = 0x0404
+
+// Resource is a Photoshop image resource block, as found in JPEG APP13
+// "Photoshop 3.0" segments
+type Resource struct {
+	ID   uint16
+	Name string
+	Data []byte
+}
+
+// ReadResources parses a sequence of "8BIM" image resource blocks
+func ReadResources(b []byte) ([]Resource, error) {
+	resources := []Resource{}
+	for len(b) != 0 {
+		if len(b) < 7 {
+			return nil, fmt.Errorf("%w: image resource block is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		if string(b[0:4]) != "8BIM" {
+			return nil, fmt.Errorf("Image resource block has signature '%s'", b[0:4])
+		}
+		id := binary.BigEndian.Uint16(b[4:6])
+
+		// The name is a Pascal string, padded to an even size including its
+		// length byte.
+		n := int(b[6])
+		nameSize := (n + 2) &^ 1
+		if len(b) < 6+nameSize+4 {
+			return nil, fmt.Errorf("%w: image resource 0x%04x name", common.ErrTruncatedSegment, id)
+		}
+		name := string(b[7 : 7+n])
+		b = b[6+nameSize:]
+
+		size := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		if uint64(size) > uint64(len(b)) {
+			return nil, fmt.Errorf("%w: image resource 0x%04x with size %d", common.ErrTruncatedSegment, id, size)
+		}
+		resources = append(resources, Resource{ID: id, Name: name, Data: b[:size]})
+
+		// The data is also padded to an even size.
+		padded := uint64(size+1) &^ 1
+		if padded > uint64(len(b)) {
+			padded = uint64(len(b))
+		}
+		b = b[padded:]
+	}
+	return resources, nil
+}
@@ -0,0 +1,181 @@
+package jfif
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// DensityUnit is the unit of the JFIF X and Y densities
+type DensityUnit uint8
+
+const (
+	// NoUnits means the densities only specify the pixel aspect ratio
+	NoUnits DensityUnit = iota
+
+	// DotsPerInch means the densities are in dots per inch
+	DotsPerInch
+
+	// DotsPerCentimeter means the densities are in dots per centimeter
+	DotsPerCentimeter
+)
+
+var densityUnits = [...]string{
+	"aspect ratio",
+	"dots per inch",
+	"dots per centimeter",
+}
+
+func (u DensityUnit) String() string {
+	if int(u) >= len(densityUnits) {
+		return "unknown"
+	}
+	return densityUnits[u]
+}
+
+// ThumbnailFormat is the encoding of a JFIF thumbnail
+type ThumbnailFormat int
+
+const (
+	// RGBThumbnail is uncompressed, with 3 bytes per pixel
+	RGBThumbnail ThumbnailFormat = iota
+
+	// JPEGThumbnail is a complete JPEG stream, from a JFXX segment
+	JPEGThumbnail
+
+	// PaletteThumbnail has 1 byte per pixel, indexing a 256 entry RGB
+	// palette, from a JFXX segment
+	PaletteThumbnail
+)
+
+var thumbnailFormats = [...]string{
+	"rgb",
+	"jpeg",
+	"palette",
+}
+
+func (f ThumbnailFormat) String() string {
+	return thumbnailFormats[f]
+}
+
+// Thumbnail is a thumbnail embedded in a JFIF or JFXX APP0 segment
+type Thumbnail struct {
+	// Format is the encoding of Data
+	Format ThumbnailFormat
+
+	// Width and Height are the dimensions of the thumbnail.  They are 0 for
+	// JPEG thumbnails, whose dimensions are in their own stream.
+	Width  uint8
+	Height uint8
+
+	// Data holds RGB pixels, palette indexes, or a JPEG stream
+	Data []byte
+
+	// Palette holds 256 RGB triplets for a palette thumbnail
+	Palette []byte
+}
+
+// JFIFHeader holds the fields of the JFIF APP0 segment
+type JFIFHeader struct {
+	// MajorVersion and MinorVersion are the JFIF version, such as 1.02
+	MajorVersion uint8
+	MinorVersion uint8
+
+	// Units is the unit of XDensity and YDensity
+	Units DensityUnit
+
+	// XDensity and YDensity are the pixel densities
+	XDensity uint16
+	YDensity uint16
+
+	// Thumbnail is the thumbnail from the JFIF segment, or from a following
+	// JFXX segment, or nil if neither holds one
+	Thumbnail *Thumbnail
+}
+
+// DPI returns the densities in dots per inch.  It returns false if the
+// densities only specify an aspect ratio.
+func (h *JFIFHeader) DPI() (float64, float64, bool) {
+	switch h.Units {
+	case DotsPerInch:
+		return float64(h.XDensity), float64(h.YDensity), true
+	case DotsPerCentimeter:
+		return float64(h.XDensity) * 2.54, float64(h.YDensity) * 2.54, true
+	}
+	return 0, 0, false
+}
+
+// JFIF returns the JFIF header, or nil if no JFIF APP0 segment has been read
+func (r *Reader) JFIF() *JFIFHeader {
+	return r.jfif
+}
+
+func (r *Reader) readJFIFSegment(b []byte) error {
+	if len(b) < 9 {
+		return fmt.Errorf("%w: JFIF segment is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	h := &JFIFHeader{
+		MajorVersion: b[0],
+		MinorVersion: b[1],
+		Units:        DensityUnit(b[2]),
+		XDensity:     binary.BigEndian.Uint16(b[3:]),
+		YDensity:     binary.BigEndian.Uint16(b[5:]),
+	}
+
+	width, height := b[7], b[8]
+	if width != 0 && height != 0 {
+		size := 3 * int(width) * int(height)
+		if len(b) < 9+size {
+			return fmt.Errorf("%w: JFIF thumbnail needs %d bytes; has %d", common.ErrTruncatedSegment, size, len(b)-9)
+		}
+		h.Thumbnail = &Thumbnail{Format: RGBThumbnail, Width: width, Height: height, Data: b[9 : 9+size]}
+	}
+
+	r.options.Logger.Debug("Read JFIF segment", "version", fmt.Sprintf("%d.%02d", h.MajorVersion, h.MinorVersion), "units", h.Units, "xDensity", h.XDensity, "yDensity", h.YDensity)
+	r.jfif = h
+	return nil
+}
+
+func (r *Reader) readJFXXSegment(b []byte) error {
+	if len(b) < 1 {
+		return fmt.Errorf("%w: JFXX segment is empty", common.ErrTruncatedSegment)
+	}
+
+	var t *Thumbnail
+	switch b[0] {
+	case 0x10:
+		t = &Thumbnail{Format: JPEGThumbnail, Data: b[1:]}
+	case 0x11:
+		if len(b) < 3+768 {
+			return fmt.Errorf("%w: JFXX palette thumbnail is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		width, height := b[1], b[2]
+		size := int(width) * int(height)
+		if len(b) < 3+768+size {
+			return fmt.Errorf("%w: JFXX thumbnail needs %d bytes; has %d", common.ErrTruncatedSegment, size, len(b)-3-768)
+		}
+		t = &Thumbnail{Format: PaletteThumbnail, Width: width, Height: height, Palette: b[3 : 3+768], Data: b[3+768 : 3+768+size]}
+	case 0x13:
+		if len(b) < 3 {
+			return fmt.Errorf("%w: JFXX RGB thumbnail is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		width, height := b[1], b[2]
+		size := 3 * int(width) * int(height)
+		if len(b) < 3+size {
+			return fmt.Errorf("%w: JFXX thumbnail needs %d bytes; has %d", common.ErrTruncatedSegment, size, len(b)-3)
+		}
+		t = &Thumbnail{Format: RGBThumbnail, Width: width, Height: height, Data: b[3 : 3+size]}
+	default:
+		r.options.Logger.Debug("Unknown JFXX extension", "code", fmt.Sprintf("0x%02x", b[0]))
+		return nil
+	}
+
+	if r.jfif == nil {
+		// JFXX segments extend the JFIF segment, which must precede them.
+		r.options.Logger.Debug("JFXX segment without JFIF segment")
+		return nil
+	}
+	r.jfif.Thumbnail = t
+	return nil
+}
@@ -0,0 +1,142 @@
+package jfif
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+const adobeIdentifier = "Adobe"
+
+// ColorTransform is the transform byte of an Adobe APP14 segment
+type ColorTransform uint8
+
+const (
+	// NoTransform means the components are RGB or CMYK, as stored
+	NoTransform ColorTransform = iota
+
+	// YCbCrTransform means 3 components are YCbCr
+	YCbCrTransform
+
+	// YCCKTransform means 4 components are YCCK
+	YCCKTransform
+)
+
+var colorTransforms = [...]string{
+	"none",
+	"YCbCr",
+	"YCCK",
+}
+
+func (t ColorTransform) String() string {
+	if int(t) >= len(colorTransforms) {
+		return "unknown"
+	}
+	return colorTransforms[t]
+}
+
+// AdobeHeader is the content of an Adobe APP14 segment
+type AdobeHeader struct {
+	// DCTEncodeVersion is the version of the encoder, usually 100
+	DCTEncodeVersion uint16
+
+	Flags0 uint16
+	Flags1 uint16
+
+	Transform ColorTransform
+}
+
+// ColorModel is the color model of the decoded components
+type ColorModel int
+
+const (
+	// UnknownColorModel is used when there is no frame, or its component count
+	// is unusual
+	UnknownColorModel ColorModel = iota
+
+	// Grayscale has a single component
+	Grayscale
+
+	// RGB has 3 components, without a transform
+	RGB
+
+	// YCbCr has 3 components
+	YCbCr
+
+	// CMYK has 4 components, without a transform.  Adobe applications write
+	// inverted CMYK.
+	CMYK
+
+	// YCCK has 4 components, transformed from CMYK
+	YCCK
+)
+
+var colorModels = [...]string{
+	"unknown",
+	"grayscale",
+	"RGB",
+	"YCbCr",
+	"CMYK",
+	"YCCK",
+}
+
+func (m ColorModel) String() string {
+	return colorModels[m]
+}
+
+// Adobe returns the content of the Adobe APP14 segment, or nil if there is
+// none
+func (r *Reader) Adobe() *AdobeHeader {
+	return r.adobe
+}
+
+// ColorModel derives the color model from the frame's component count, along
+// with the Adobe transform, the presence of a JFIF segment, and the component
+// IDs, as libjpeg does
+func (r *Reader) ColorModel() ColorModel {
+	if r.frame == nil {
+		return UnknownColorModel
+	}
+
+	switch len(r.frame.Components) {
+	case 1:
+		return Grayscale
+	case 3:
+		if r.adobe != nil {
+			if r.adobe.Transform == NoTransform {
+				return RGB
+			}
+			return YCbCr
+		}
+		if r.jfif != nil {
+			return YCbCr
+		}
+		c := r.frame.Components
+		if c[0].ID == 'R' && c[1].ID == 'G' && c[2].ID == 'B' {
+			return RGB
+		}
+		return YCbCr
+	case 4:
+		if r.adobe != nil && r.adobe.Transform == YCCKTransform {
+			return YCCK
+		}
+		return CMYK
+	}
+	return UnknownColorModel
+}
+
+func (r *Reader) readAdobeSegment(b []byte) error {
+	if len(b) < 7 {
+		return fmt.Errorf("%w: Adobe segment is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	a := &AdobeHeader{
+		DCTEncodeVersion: binary.BigEndian.Uint16(b[0:]),
+		Flags0:           binary.BigEndian.Uint16(b[2:]),
+		Flags1:           binary.BigEndian.Uint16(b[4:]),
+		Transform:        ColorTransform(b[6]),
+	}
+	r.options.Logger.Debug("Read Adobe segment", "version", a.DCTEncodeVersion, "transform", a.Transform)
+	r.adobe = a
+	return nil
+}
@@ -0,0 +1,43 @@
+package jfif
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Comment is the content of a COM segment
+type Comment struct {
+	// Raw holds the bytes of the segment
+	Raw []byte
+
+	// Text is the comment decoded as UTF-8 if it is valid, or as Latin-1
+	// otherwise, without trailing NULs
+	Text string
+}
+
+// Comments returns the COM segments, in stream order
+func (r *Reader) Comments() []Comment {
+	return r.comments
+}
+
+func (r *Reader) readCOMSegment() error {
+	payload, err := r.readSegmentPayload()
+	if err != nil {
+		return err
+	}
+	c := Comment{Raw: payload, Text: decodeText(bytes.TrimRight(payload, "\x00"))}
+	r.options.Logger.Debug("Read COM segment", "length", len(payload))
+	r.comments = append(r.comments, c)
+	return nil
+}
+
+// decodeText decodes bytes of an unknown character set
+func decodeText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	return common.DecodeLatin1(b)
+}
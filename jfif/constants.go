@@ -3,15 +3,25 @@ package jfif
 type marker uint16
 
 const (
-	soi  marker = 0xffd8
-	sof0        = 0xffc0
-	sof2        = 0xffc2
-	dht         = 0xffc4
-	dqt         = 0xffdb
-	dri         = 0xffdd
-	sos         = 0xffda
-	rstn        = 0xffd0 // 0xffd0 to 0xffd7
-	appn        = 0xffe0 // 0xffe0 to 0ffxef
-	com         = 0xfffe
-	eoi         = 0xffd9
+	soi   marker = 0xffd8
+	sof0         = 0xffc0 // 0xffc0 to 0xffcf, except dht, jpg and dac
+	sof2         = 0xffc2
+	sof15        = 0xffcf
+	dht          = 0xffc4
+	jpg          = 0xffc8
+	dac          = 0xffcc
+	dqt          = 0xffdb
+	dri          = 0xffdd
+	sos          = 0xffda
+	rstn         = 0xffd0 // 0xffd0 to 0xffd7
+	rst7         = 0xffd7
+	tem          = 0xff01
+	appn         = 0xffe0 // 0xffe0 to 0xffef
+	app0         = 0xffe0
+	app1         = 0xffe1
+	app2         = 0xffe2
+	app13        = 0xffed
+	app14        = 0xffee
+	com          = 0xfffe
+	eoi          = 0xffd9
 )
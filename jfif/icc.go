@@ -0,0 +1,37 @@
+package jfif
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/icc"
+)
+
+const iccIdentifier = "ICC_PROFILE"
+
+// ICCProfile returns the embedded ICC profile, or nil if the stream has none,
+// or its chunks are incomplete
+func (r *Reader) ICCProfile() *icc.Profile {
+	return r.iccProfile
+}
+
+func (r *Reader) readICCSegment(b []byte) error {
+	if err := r.iccChunks.AddChunk(b); err != nil {
+		return fmt.Errorf("Failed to read ICC profile segment: %w", err)
+	}
+	return nil
+}
+
+// resolveICCProfile parses the profile, once all its chunks have been read.
+// An incomplete or corrupt profile is dropped.
+func (r *Reader) resolveICCProfile() {
+	if r.iccChunks.Empty() {
+		return
+	}
+	b, err := r.iccChunks.Assemble()
+	if err == nil {
+		r.iccProfile, err = icc.Parse(b)
+	}
+	if err != nil {
+		r.options.Logger.Debug("Ignoring ICC profile", "err", err)
+	}
+}
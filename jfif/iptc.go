@@ -0,0 +1,37 @@
+package jfif
+
+import (
+	"github.com/object88/go-image-metadata/iptc"
+)
+
+const photoshopIdentifier = "Photoshop 3.0"
+
+// IPTC returns the IPTC-IIM datasets from the Photoshop image resources, or
+// nil if the stream has none
+func (r *Reader) IPTC() *iptc.IPTC {
+	return r.iptc
+}
+
+// resolvePhotoshop parses the image resources, once every APP13 segment has
+// been read, as a large resource may be split across segments.  Corrupt
+// resources are dropped.
+func (r *Reader) resolvePhotoshop() {
+	if len(r.photoshop) == 0 {
+		return
+	}
+	resources, err := iptc.ReadResources(r.photoshop)
+	if err != nil {
+		r.options.Logger.Debug("Ignoring Photoshop image resources", "err", err)
+		return
+	}
+	for _, res := range resources {
+		if res.ID != iptc.IPTCResource {
+			continue
+		}
+		r.iptc, err = iptc.Parse(res.Data)
+		if err != nil {
+			r.options.Logger.Debug("Ignoring IPTC-IIM resource", "err", err)
+		}
+		return
+	}
+}
@@ -1,12 +1,20 @@
 package jfif
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/iptc"
 	"github.com/object88/go-image-metadata/reader"
 	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+
+	// Exif and MPF segments are TIFF structures
+	_ "github.com/object88/go-image-metadata/tiff"
 )
 
 func init() {
@@ -15,124 +23,324 @@ func init() {
 
 // Reader understands a Jfif byte stream
 type Reader struct {
-	r reader.Reader
+	r       reader.Reader
+	options *metadata.Options
+	frame   *Frame
+	jfif    *JFIFHeader
+	adobe   *AdobeHeader
+
+	xmp         *xmp.Packet
+	extendedXmp xmp.Extended
+
+	iccProfile *icc.Profile
+	iccChunks  icc.Chunks
+
+	iptc      *iptc.IPTC
+	photoshop []byte
+
+	mpf *MPF
+
+	comments []Comment
+
+	segments []Segment
+	trailer  *Trailer
+
+	quantizationTables []QuantizationTable
+	huffmanTables      []HuffmanTable
 }
 
 // CheckHeader checks the byte stream to see if it contains a JFIF
-func CheckHeader(r io.ReadSeeker) (metadata.ImageReader, error) {
-	fmt.Printf("Checking jfif header... ")
-	cur, _ := r.Seek(0, io.SeekCurrent)
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
 	b := []byte{0x00, 0x00}
-	n, err := r.Read(b)
-	if n != 2 || err != nil {
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a JFIF
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
 
 	if b[0] != 0xff || b[1] != 0xd8 {
-		fmt.Printf("got %#v; was wrong\n", b)
 		return nil, nil
 	}
-	fmt.Printf("matched\n")
-	return &Reader{r: reader.CreateBigEndianReader(r, cur)}, nil
+	options.Logger.Debug("Matched JFIF header", "offset", cur)
+	return &Reader{r: reader.CreateBigEndianReader(r, cur, options.Logger), options: options}, nil
 }
 
-func (r *Reader) Read() map[uint16]tags.Tag {
-	m := map[uint16]tags.Tag{}
-	r.ReadPartial(&m)
-	return m
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
 }
 
-func (r *Reader) ReadPartial(foundTags *map[uint16]tags.Tag) int64 {
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+
+	// CheckHeader has already read the SOI marker.
+	r.segments = append(r.segments, Segment{Marker: uint16(soi), Offset: start - 2})
+
 	// Loop over marker segments
 	for {
-		m, e := r.r.ReadUint16()
-		if e != nil {
-			panic(fmt.Errorf("NOOOOO: %s", e))
+		m, err := r.readMarker()
+		if err != nil {
+			return 0, err
 		}
 
-		fmt.Printf("0x%04x; ", m)
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return 0, err
+		}
+		r.options.Logger.Debug("Read marker", "marker", fmt.Sprintf("0x%04x", m), "offset", cur-2)
 
+		seg := Segment{Marker: m, Offset: cur - 2}
 		m1 := marker(m)
 		if m1 == eoi {
 			// We have reached the end of the file.
-			fmt.Printf("DONE\n")
+			r.segments = append(r.segments, seg)
+			if err = r.resolveSegments(); err != nil {
+				return 0, err
+			}
+			if err = r.readTrailer(); err != nil {
+				return 0, err
+			}
+			break
+		}
+		if m1 == sos && r.options.MetadataOnly {
+			// All the metadata segments precede the first scan.
+			r.options.Logger.Debug("Stopping at image data", "offset", cur-2)
+			r.segments = append(r.segments, seg)
+			if err = r.resolveSegments(); err != nil {
+				return 0, err
+			}
 			break
 		}
 
-		if m&0xffe0 == 0xffe0 {
+		if m&0xfff0 == appn {
 			// We have an appN segment.
-			r.readAppnSegment(foundTags)
-		} else if m1 == soi || m^0xffd0>>3 == 0 {
-			// Restart: 0xffd0-0xffd7; nothing to process.
-			fmt.Printf("got restart\n")
-			continue
+			seg.Identifier, seg.Parsed, err = r.readAppnSegment(m, tree)
+		} else if m1 == com {
+			err = r.readCOMSegment()
+			seg.Parsed = err == nil
+		} else if m1 == soi || m1 == tem {
+			// Standalone markers, without a length; nothing to process.
+		} else if m >= rstn && m <= rst7 {
+			// Restart markers are standalone, and interrupt the entropy-coded
+			// data, which continues after them.
+			seg.DataLength, err = r.movePastImageData()
+		} else if m1 == dqt {
+			err = r.readDQTSegment()
+			seg.Parsed = err == nil
+		} else if m1 == dht {
+			err = r.readDHTSegment()
+			seg.Parsed = err == nil
+		} else if isSOF(m) {
+			err = r.readSOFSegment(m)
+			seg.Parsed = err == nil
 		} else if m1 == sos {
 			// This is the beginning of the image data.  We want to scan past all
 			// this, but we don't have a length.
-			r.movePastImageSegment()
+			seg.Length, seg.DataLength, err = r.movePastImageSegment()
 		} else {
-			r.moveToNextSegment()
+			err = r.moveToNextSegment()
+		}
+		if err != nil {
+			return 0, err
 		}
+
+		if m1 != sos {
+			end, err := r.r.GetCurrentOffset()
+			if err != nil {
+				return 0, err
+			}
+			seg.Length = end - cur - seg.DataLength
+		}
+		r.segments = append(r.segments, seg)
 	}
-	return 0
-}
 
-func (r *Reader) readAppnSegment(foundTags *map[uint16]tags.Tag) {
-	fmt.Printf("app segment")
-	rem, err := r.r.ReadUint16()
+	cur, err := r.r.GetCurrentOffset()
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
+	return cur - start, nil
+}
 
-	remaining := int64(rem) - 2
-	fmt.Printf("; will read %d bytes", remaining)
+// resolveSegments processes the content which may be split across several
+// segments or images, once the segments of the primary image have all been
+// read
+func (r *Reader) resolveSegments() error {
+	r.resolveExtendedXMP()
+	r.resolveICCProfile()
+	r.resolvePhotoshop()
+	return r.resolveMPF()
+}
 
-	id, err := r.r.ReadNullTerminatedString()
+// readAppnSegment reads an APPn segment, returning its identifier and whether
+// its content was recognized and parsed.  Only a failure to read the segment
+// itself, or its Exif data, is returned as an error; any other malformed
+// payload is logged and skipped.
+func (r *Reader) readAppnSegment(m uint16, tree *tags.Tree) (string, bool, error) {
+	payload, err := r.readSegmentPayload()
 	if err != nil {
-		panic(err)
+		return "", false, err
 	}
-	fmt.Printf("; found identifier: '%s' (%d)", id, len(id))
 
-	remaining -= int64(len(id))
+	id := identifier(payload)
+	r.options.Logger.Debug("Read APPn segment", "marker", fmt.Sprintf("0x%04x", m), "identifier", id, "length", len(payload))
 
 	// Need to check the type of app segment by the null-terminated string, then
 	// act appropriately.
-	switch id {
-	case "Exif":
-		fmt.Printf(" **WOOOO**\n")
+	switch {
+	case m == app0 && id == "JFIF":
+		err = r.readJFIFSegment(payload[len(id)+1:])
+	case m == app0 && id == "JFXX":
+		err = r.readJFXXSegment(payload[len(id)+1:])
+	case m == app1 && id == "Exif":
 		// The `Exif` string is double-null terminated:
 		// https://www.media.mit.edu/pia/Research/deepview/exif.html
-		r.r.Discard(2)
-		remaining -= 2
-		r1, err := metadata.ReadHeader(r.r.GetReader())
-		if err != nil {
-			panic("NOPE")
+		// The Exif IFDs are the tree being read, so unlike the other payloads, a
+		// failure here is returned.
+		if err = r.readExifSegment(payload[len(id)+1:], tree); err != nil {
+			return id, false, err
 		}
-		consumed := r1.ReadPartial(foundTags)
-		remaining -= consumed
-
-		r.r.Discard(int64(remaining))
+	case m == app1 && id == xmpIdentifier:
+		err = r.readXMPSegment(payload[len(id)+1:])
+	case m == app1 && id == extendedXmpIdentifier:
+		err = r.readExtendedXMPSegment(payload[len(id)+1:])
+	case m == app2 && id == iccIdentifier:
+		err = r.readICCSegment(payload[len(id)+1:])
+	case m == app2 && id == mpfIdentifier:
+		end, err1 := r.r.GetCurrentOffset()
+		if err1 != nil {
+			return id, false, err1
+		}
+		start := end - int64(len(payload)) + int64(len(id)+1)
+		err = r.readMPFSegment(payload[len(id)+1:], start, tree)
+	case m == app13 && id == photoshopIdentifier:
+		r.photoshop = append(r.photoshop, payload[len(id)+1:]...)
+	case m == app14 && bytes.HasPrefix(payload, []byte(adobeIdentifier)):
+		// The `Adobe` string is not NUL-terminated, although the version which
+		// follows usually starts with 0x00.
+		id = adobeIdentifier
+		err = r.readAdobeSegment(payload[len(adobeIdentifier):])
 	default:
-		r.r.Discard(int64(remaining))
+		return id, false, nil
 	}
+
+	if err != nil {
+		// The whole segment has been read, so a malformed payload costs only
+		// this segment; the frame and tables which follow are still needed.
+		r.options.Logger.Debug("Ignoring APPn segment", "marker", fmt.Sprintf("0x%04x", m), "identifier", id, "err", err)
+		return id, false, nil
+	}
+	return id, true, nil
 }
 
-func (r *Reader) moveToNextSegment() {
-	// Ignore this segment.  Need to read the variable length, and scan past it.
+func (r *Reader) readExifSegment(b []byte, tree *tags.Tree) error {
+	if len(b) < 1 {
+		return fmt.Errorf("%w: Exif segment has no TIFF header", common.ErrTruncatedSegment)
+	}
+	r1, err := metadata.ReadHeader(bytes.NewReader(b[1:]), metadata.WithOptions(r.options))
+	if err != nil {
+		return fmt.Errorf("Failed to read Exif segment: %w", err)
+	}
+	_, err = r1.ReadPartial(tree)
+	if err != nil {
+		return fmt.Errorf("Failed to read Exif segment: %w", err)
+	}
+	return nil
+}
+
+// identifier returns the NUL-terminated string which starts an APPn segment,
+// or an empty string if there is no NUL.
+func identifier(payload []byte) string {
+	n := bytes.IndexByte(payload, 0)
+	if n < 0 {
+		return ""
+	}
+	return string(payload[:n])
+}
+
+// readSegmentPayload reads the length which follows a marker, and then the
+// rest of the segment.
+func (r *Reader) readSegmentPayload() ([]byte, error) {
+	end, err := r.readSegmentEnd()
+	if err != nil {
+		return nil, err
+	}
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return nil, err
+	}
+	return r.r.ReadBytes(int(end - cur))
+}
+
+// readSegmentEnd reads the 2 byte length which follows most markers, and
+// returns the offset of the first byte after the segment.
+func (r *Reader) readSegmentEnd() (int64, error) {
 	s, err := r.r.ReadUint16()
 	if err != nil {
-		panic(err)
+		return 0, err
+	}
+	if s < 2 {
+		return 0, fmt.Errorf("%w: segment length %d is shorter than its own length field", common.ErrTruncatedSegment, s)
+	}
+
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
 	}
+	return cur + int64(s) - 2, nil
+}
+
+func (r *Reader) moveToNextSegment() error {
+	// Ignore this segment.  Need to read the variable length, and scan past it.
+	end, err := r.readSegmentEnd()
+	if err != nil {
+		return err
+	}
+	return r.r.SeekTo(end)
+}
 
-	s0 := s - 2
-	if s0 > 0 {
-		r.r.Discard(int64(s0))
+// movePastImageSegment skips the scan header, and then the entropy-coded data
+// which follows it, returning the length of each
+func (r *Reader) movePastImageSegment() (int64, int64, error) {
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := r.moveToNextSegment(); err != nil {
+		return 0, 0, err
 	}
+	end, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := r.movePastImageData()
+	return end - cur, n, err
 }
 
-func (r *Reader) movePastImageSegment() {
-	// There is no length in the SOS marker segment; it is just a stream of bytes
-	// until we encounter 0xFF which is not immediately followed by 0x00
+// movePastImageData scans past entropy-coded data, returning its length
+func (r *Reader) movePastImageData() (int64, error) {
+	// There is no length for the entropy-coded data; it is just a stream of
+	// bytes until we encounter 0xFF which is not immediately followed by 0x00
 	// http://stackoverflow.com/questions/26715684/parsing-jpeg-sos-marker
-	r.r.ReadTo()
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.r.ReadTo(); err != nil {
+		return 0, err
+	}
+	end, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	return end - cur, nil
 }
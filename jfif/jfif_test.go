@@ -0,0 +1,862 @@
+package jfif_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/jfif"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+)
+
+// segment encodes a marker segment, with a length covering the payload
+func segment(marker uint16, payload []byte) []byte {
+	b := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint16(b, marker)
+	binary.BigEndian.PutUint16(b[2:], uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+// buildJpeg wraps the segments with SOI and EOI markers
+func buildJpeg(segments ...[]byte) []byte {
+	b := []byte{0xff, 0xd8}
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+	return append(b, 0xff, 0xd9)
+}
+
+func readJpeg(t *testing.T, b []byte) *jfif.Reader {
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	_, err = ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading segments: %s\n", err)
+	}
+	return ir.(*jfif.Reader)
+}
+
+func Test_Frame(t *testing.T) {
+	var tcs = []struct {
+		name        string
+		marker      uint16
+		components  []byte
+		process     jfif.CodingProcess
+		arithmetic  bool
+		subsampling string
+	}{
+		{"baseline 4:2:0", 0xffc0, []byte{1, 0x22, 0, 2, 0x11, 1, 3, 0x11, 1}, jfif.Baseline, false, "4:2:0"},
+		{"progressive 4:2:2", 0xffc2, []byte{1, 0x21, 0, 2, 0x11, 1, 3, 0x11, 1}, jfif.Progressive, false, "4:2:2"},
+		{"arithmetic 4:4:4", 0xffc9, []byte{1, 0x11, 0, 2, 0x11, 1, 3, 0x11, 1}, jfif.ExtendedSequential, true, "4:4:4"},
+		{"lossless grayscale", 0xffc3, []byte{1, 0x11, 0}, jfif.Lossless, false, ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := []byte{8, 0x01, 0xe0, 0x02, 0x80, byte(len(tc.components) / 3)}
+			payload = append(payload, tc.components...)
+			r := readJpeg(t, buildJpeg(segment(0xffdd, []byte{0, 0}), segment(tc.marker, payload)))
+
+			f := r.Frame()
+			if f == nil {
+				t.Fatalf("Expected frame; got nil")
+			}
+			if f.Width != 640 || f.Height != 480 || f.Precision != 8 {
+				t.Fatalf("Expected 640x480 at 8 bits; got %dx%d at %d bits", f.Width, f.Height, f.Precision)
+			}
+			if f.Process != tc.process || f.Arithmetic != tc.arithmetic {
+				t.Fatalf("Expected %s (arithmetic: %t); got %s (arithmetic: %t)", tc.process, tc.arithmetic, f.Process, f.Arithmetic)
+			}
+			if len(f.Components) != len(tc.components)/3 {
+				t.Fatalf("Expected %d components; got %d", len(tc.components)/3, len(f.Components))
+			}
+			if f.ChromaSubsampling() != tc.subsampling {
+				t.Fatalf("Expected subsampling '%s'; got '%s'", tc.subsampling, f.ChromaSubsampling())
+			}
+		})
+	}
+}
+
+func Test_JFIF(t *testing.T) {
+	palette := make([]byte, 768)
+	var tcs = []struct {
+		name      string
+		segments  [][]byte
+		units     jfif.DensityUnit
+		dpi       float64
+		thumbnail *jfif.Thumbnail
+	}{
+		{"dpi", [][]byte{segment(0xffe0, []byte("JFIF\x00\x01\x02\x01\x00\x48\x00\x48\x00\x00"))}, jfif.DotsPerInch, 72, nil},
+		{"dpcm", [][]byte{segment(0xffe0, []byte("JFIF\x00\x01\x01\x02\x00\x64\x00\x64\x00\x00"))}, jfif.DotsPerCentimeter, 254, nil},
+		{"aspect ratio", [][]byte{segment(0xffe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))}, jfif.NoUnits, 0, nil},
+		{
+			"rgb thumbnail",
+			[][]byte{segment(0xffe0, []byte("JFIF\x00\x01\x01\x01\x00\x48\x00\x48\x01\x01\x10\x20\x30"))},
+			jfif.DotsPerInch, 72,
+			&jfif.Thumbnail{Format: jfif.RGBThumbnail, Width: 1, Height: 1, Data: []byte{0x10, 0x20, 0x30}},
+		},
+		{
+			"jfxx jpeg thumbnail",
+			[][]byte{
+				segment(0xffe0, []byte("JFIF\x00\x01\x02\x01\x00\x48\x00\x48\x00\x00")),
+				segment(0xffe0, []byte("JFXX\x00\x10\xff\xd8\xff\xd9")),
+			},
+			jfif.DotsPerInch, 72,
+			&jfif.Thumbnail{Format: jfif.JPEGThumbnail, Data: []byte{0xff, 0xd8, 0xff, 0xd9}},
+		},
+		{
+			"jfxx palette thumbnail",
+			[][]byte{
+				segment(0xffe0, []byte("JFIF\x00\x01\x02\x01\x00\x48\x00\x48\x00\x00")),
+				segment(0xffe0, append(append([]byte("JFXX\x00\x11\x02\x01"), palette...), 7, 9)),
+			},
+			jfif.DotsPerInch, 72,
+			&jfif.Thumbnail{Format: jfif.PaletteThumbnail, Width: 2, Height: 1, Palette: palette, Data: []byte{7, 9}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := readJpeg(t, buildJpeg(tc.segments...))
+
+			h := r.JFIF()
+			if h == nil {
+				t.Fatalf("Expected JFIF header; got nil")
+			}
+			if h.Units != tc.units {
+				t.Fatalf("Expected units %s; got %s", tc.units, h.Units)
+			}
+			x, y, ok := h.DPI()
+			if ok != (tc.units != jfif.NoUnits) || x != tc.dpi || y != tc.dpi {
+				t.Fatalf("Expected %f dpi; got %f, %f, %t", tc.dpi, x, y, ok)
+			}
+			if !reflect.DeepEqual(h.Thumbnail, tc.thumbnail) {
+				t.Fatalf("Expected thumbnail %v; got %v", tc.thumbnail, h.Thumbnail)
+			}
+		})
+	}
+}
+
+const xmpMain = `<?xpacket begin="\xef\xbb\xbf" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:exif="http://ns.adobe.com/exif/1.0/"
+    xmlns:xmpNote="http://ns.adobe.com/xmp/note/"
+    xmp:Rating="4"
+    xmpNote:HasExtendedXMP="%s">
+   <dc:title>
+    <rdf:Alt>
+     <rdf:li xml:lang="x-default">Harbour</rdf:li>
+     <rdf:li xml:lang="fr-FR">Port</rdf:li>
+    </rdf:Alt>
+   </dc:title>
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>boats</rdf:li>
+     <rdf:li>sea</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <exif:Flash rdf:parseType="Resource">
+    <exif:Fired>False</exif:Fired>
+   </exif:Flash>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+const xmpExtended = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/">
+   <photoshop:History>edited</photoshop:History>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+// extendedChunk encodes an APP1 segment with part of an Extended XMP packet
+func extendedChunk(guid string, full []byte, offset, length int) []byte {
+	payload := append([]byte("http://ns.adobe.com/xmp/extension/\x00"), guid...)
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(full)))
+	payload = binary.BigEndian.AppendUint32(payload, uint32(offset))
+	payload = append(payload, full[offset:offset+length]...)
+	return segment(0xffe1, payload)
+}
+
+func Test_XMP(t *testing.T) {
+	ext := []byte(xmpExtended)
+	guid := fmt.Sprintf("%X", md5.Sum(ext))
+	half := len(ext) / 2
+
+	var tcs = []struct {
+		name     string
+		guid     string
+		length   uint32
+		extended bool
+	}{
+		{"matching digest", guid, 0, true},
+		{"mismatched digest", "00000000000000000000000000000000", 0, false},
+		{"oversized length", guid, 0xffffffff, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			main := segment(0xffe1, append([]byte("http://ns.adobe.com/xap/1.0/\x00"), fmt.Sprintf(xmpMain, tc.guid)...))
+
+			// Chunks are deliberately out of order.
+			chunks := [][]byte{
+				extendedChunk(tc.guid, ext, half, len(ext)-half),
+				extendedChunk(tc.guid, ext, 0, half),
+			}
+			if tc.length != 0 {
+				// Overwrite the full length, after the marker, length, identifier
+				// and GUID
+				for _, c := range chunks {
+					binary.BigEndian.PutUint32(c[4+35+32:], tc.length)
+				}
+			}
+			r := readJpeg(t, buildJpeg(main, chunks[0], chunks[1]))
+
+			p := r.XMP()
+			if p == nil {
+				t.Fatalf("Expected XMP packet; got nil")
+			}
+
+			paths := map[string]string{}
+			p.Walk(func(path string, n *xmp.Node) bool {
+				paths[path] = n.Value
+				return true
+			})
+			expected := map[string]string{
+				"xmp:Rating":            "4",
+				"dc:title[2]":           "Port",
+				"dc:subject[1]":         "boats",
+				"dc:subject[2]":         "sea",
+				"exif:Fired":            "",
+				"exif:Flash/exif:Fired": "False",
+			}
+			for path, value := range expected {
+				if path == "exif:Fired" {
+					if _, ok := paths[path]; ok {
+						t.Fatalf("Expected struct field to be nested; got top-level %s", path)
+					}
+					continue
+				}
+				if paths[path] != value {
+					t.Fatalf("Expected %s to be '%s'; got '%s'", path, value, paths[path])
+				}
+			}
+
+			title, ok := p.Get("http://purl.org/dc/elements/1.1/", "title")
+			if !ok || title.Kind != xmp.Alt {
+				t.Fatalf("Expected dc:title language alternative; got %v", title)
+			}
+			if s, _ := title.LocalizedText("fr-FR"); s != "Port" {
+				t.Fatalf("Expected French title 'Port'; got '%s'", s)
+			}
+			if s, _ := title.LocalizedText("de-DE"); s != "Harbour" {
+				t.Fatalf("Expected default title 'Harbour'; got '%s'", s)
+			}
+
+			history, ok := p.Get("http://ns.adobe.com/photoshop/1.0/", "History")
+			if ok != tc.extended {
+				t.Fatalf("Expected extended property present: %t; got %t", tc.extended, ok)
+			}
+			if tc.extended && (history.Value != "edited" || !bytes.Equal(p.ExtendedRaw, ext)) {
+				t.Fatalf("Expected reassembled extended packet; got '%s'", history.Value)
+			}
+		})
+	}
+}
+
+// buildICC encodes a v4 display profile with 'desc' and 'wtpt' tags
+func buildICC(description string) []byte {
+	text := utf16.Encode([]rune(description))
+	desc := append([]byte("mluc"), 0, 0, 0, 0)
+	desc = binary.BigEndian.AppendUint32(desc, 1)
+	desc = binary.BigEndian.AppendUint32(desc, 12)
+	desc = append(desc, "enUS"...)
+	desc = binary.BigEndian.AppendUint32(desc, uint32(len(text)*2))
+	desc = binary.BigEndian.AppendUint32(desc, 28)
+	for _, u := range text {
+		desc = binary.BigEndian.AppendUint16(desc, u)
+	}
+
+	wtpt := append([]byte("XYZ "), 0, 0, 0, 0)
+	for _, v := range []uint32{0xf6d6, 0x10000, 0xd32d} {
+		wtpt = binary.BigEndian.AppendUint32(wtpt, v)
+	}
+
+	b := make([]byte, 128)
+	copy(b[4:], "appl")
+	b[8], b[9] = 4, 0x30
+	copy(b[12:], "mntrRGB XYZ ")
+	copy(b[36:], "acsp")
+	binary.BigEndian.PutUint32(b[64:], 1)
+	b = binary.BigEndian.AppendUint32(b, 2)
+	offset := uint32(128 + 4 + 2*12)
+	b = append(b, "desc"...)
+	b = binary.BigEndian.AppendUint32(b, offset)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(desc)))
+	b = append(b, "wtpt"...)
+	b = binary.BigEndian.AppendUint32(b, offset+uint32(len(desc)))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(wtpt)))
+	b = append(b, desc...)
+	b = append(b, wtpt...)
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+// iccChunk encodes an APP2 segment with one chunk of an ICC profile
+func iccChunk(seq, count byte, data []byte) []byte {
+	payload := append([]byte("ICC_PROFILE\x00"), seq, count)
+	return segment(0xffe2, append(payload, data...))
+}
+
+func Test_ICCProfile(t *testing.T) {
+	profile := buildICC("Display P3")
+	half := len(profile) / 2
+
+	var tcs = []struct {
+		name     string
+		segments [][]byte
+		expected bool
+	}{
+		{"single chunk", [][]byte{iccChunk(1, 1, profile)}, true},
+		{"out of order chunks", [][]byte{iccChunk(2, 2, profile[half:]), iccChunk(1, 2, profile[:half])}, true},
+		{"missing chunk", [][]byte{iccChunk(1, 2, profile[:half])}, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := readJpeg(t, buildJpeg(tc.segments...))
+
+			p := r.ICCProfile()
+			if !tc.expected {
+				if p != nil {
+					t.Fatalf("Expected no profile; got %v", p)
+				}
+				return
+			}
+			if p == nil {
+				t.Fatalf("Expected profile; got nil")
+			}
+			if p.Class != icc.DisplayClass || p.ColorSpace != "RGB" || p.PCS != "XYZ" {
+				t.Fatalf("Expected display RGB/XYZ profile; got %s %s/%s", p.Class, p.ColorSpace, p.PCS)
+			}
+			if p.Version.String() != "4.3.0" || p.RenderingIntent != icc.RelativeColorimetric {
+				t.Fatalf("Expected version 4.3.0, relative colorimetric; got %s, %s", p.Version, p.RenderingIntent)
+			}
+			if p.Description != "Display P3" {
+				t.Fatalf("Expected description 'Display P3'; got '%s'", p.Description)
+			}
+			if p.WhitePoint == nil || p.WhitePoint.Y != 1 || p.WhitePoint.X < 0.964 || p.WhitePoint.X > 0.965 {
+				t.Fatalf("Expected D50 white point; got %v", p.WhitePoint)
+			}
+		})
+	}
+}
+
+// dataset encodes an IPTC-IIM dataset
+func dataset(record, number byte, value string) []byte {
+	b := []byte{0x1c, record, number}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+// photoshopSegments encodes IPTC-IIM datasets in an 8BIM resource, split
+// across the provided number of APP13 segments
+func photoshopSegments(parts int, datasets ...[]byte) [][]byte {
+	iim := bytes.Join(datasets, nil)
+	resources := append([]byte("8BIM\x03\xed\x00\x00"), 0, 0, 0, 0x10)
+	resources = append(resources, make([]byte, 0x10)...)
+	resources = append(resources, "8BIM\x04\x04\x00\x00"...)
+	resources = binary.BigEndian.AppendUint32(resources, uint32(len(iim)))
+	resources = append(resources, iim...)
+	if len(iim)%2 == 1 {
+		resources = append(resources, 0)
+	}
+
+	segments := [][]byte{}
+	size := (len(resources) + parts - 1) / parts
+	for start := 0; start < len(resources); start += size {
+		end := min(start+size, len(resources))
+		payload := append([]byte("Photoshop 3.0\x00"), resources[start:end]...)
+		segments = append(segments, segment(0xffed, payload))
+	}
+	return segments
+}
+
+func Test_IPTC(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		parts    int
+		charset  []byte
+		byline   string
+		expected string
+	}{
+		{"latin-1", 1, nil, "Fran\xe7ois", "François"},
+		{"utf-8", 1, dataset(1, 90, "\x1b%G"), "François", "François"},
+		{"split segments", 2, dataset(1, 90, "\x1b%G"), "François", "François"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := readJpeg(t, buildJpeg(photoshopSegments(tc.parts,
+				tc.charset,
+				dataset(2, 0, "\x00\x04"),
+				dataset(2, 80, tc.byline),
+				dataset(2, 25, "harbour"),
+				dataset(2, 25, "boats"),
+				dataset(2, 120, "Boats in the harbour"),
+			)...))
+
+			i := r.IPTC()
+			if i == nil {
+				t.Fatalf("Expected IPTC; got nil")
+			}
+			if v := i.Get("By-line"); len(v) != 1 || v[0] != tc.expected {
+				t.Fatalf("Expected By-line '%s'; got %q", tc.expected, v)
+			}
+			if v := i.Get("Keywords"); !reflect.DeepEqual(v, []string{"harbour", "boats"}) {
+				t.Fatalf("Expected repeated Keywords; got %q", v)
+			}
+			fields := i.Fields()
+			if v := fields["RecordVersion"]; len(v) != 1 || v[0] != "4" {
+				t.Fatalf("Expected RecordVersion 4; got %q", v)
+			}
+			if v := fields["Caption-Abstract"]; len(v) != 1 || v[0] != "Boats in the harbour" {
+				t.Fatalf("Expected caption; got %q", v)
+			}
+		})
+	}
+}
+
+func Test_ColorModel(t *testing.T) {
+	sof := func(ids ...byte) []byte {
+		payload := []byte{8, 0, 16, 0, 16, byte(len(ids))}
+		for _, id := range ids {
+			payload = append(payload, id, 0x11, 0)
+		}
+		return segment(0xffc0, payload)
+	}
+	adobe := func(transform byte) []byte {
+		return segment(0xffee, []byte{'A', 'd', 'o', 'b', 'e', 0, 100, 0, 0, 0, 0, transform})
+	}
+	jfifSegment := segment(0xffe0, []byte{'J', 'F', 'I', 'F', 0, 1, 2, 0, 0, 1, 0, 1, 0, 0})
+
+	var tcs = []struct {
+		name     string
+		segments [][]byte
+		expected jfif.ColorModel
+	}{
+		{"no frame", nil, jfif.UnknownColorModel},
+		{"grayscale", [][]byte{sof(1)}, jfif.Grayscale},
+		{"JFIF", [][]byte{jfifSegment, sof(1, 2, 3)}, jfif.YCbCr},
+		{"Adobe RGB", [][]byte{adobe(0), sof(1, 2, 3)}, jfif.RGB},
+		{"Adobe YCbCr", [][]byte{adobe(1), sof(1, 2, 3)}, jfif.YCbCr},
+		{"RGB component IDs", [][]byte{sof('R', 'G', 'B')}, jfif.RGB},
+		{"Adobe CMYK", [][]byte{adobe(0), sof(1, 2, 3, 4)}, jfif.CMYK},
+		{"Adobe YCCK", [][]byte{adobe(2), sof(1, 2, 3, 4)}, jfif.YCCK},
+		{"CMYK without Adobe", [][]byte{sof(1, 2, 3, 4)}, jfif.CMYK},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := readJpeg(t, buildJpeg(tc.segments...))
+			if m := r.ColorModel(); m != tc.expected {
+				t.Fatalf("Expected color model %s; got %s", tc.expected, m)
+			}
+		})
+	}
+
+	r := readJpeg(t, buildJpeg(adobe(2)))
+	a := r.Adobe()
+	if a == nil || a.DCTEncodeVersion != 100 || a.Transform != jfif.YCCKTransform {
+		t.Fatalf("Expected Adobe version 100 with YCCK transform; got %v", a)
+	}
+}
+
+// buildMPF encodes a little-endian MPF APP2 segment, with an MP Index IFD
+// listing the two images, followed by an MP Attribute IFD
+func buildMPF(primarySize, secondaryOffset, secondarySize uint32) []byte {
+	order := binary.LittleEndian
+	b := []byte("MPF\x00II\x2a\x00\x08\x00\x00\x00")
+	entry := func(tag, format uint16, count, data uint32) {
+		b = order.AppendUint16(b, tag)
+		b = order.AppendUint16(b, format)
+		b = order.AppendUint32(b, count)
+		b = order.AppendUint32(b, data)
+	}
+
+	// MP Index IFD at 8, its entries at 50, and the MP Attribute IFD at 82
+	b = order.AppendUint16(b, 3)
+	entry(0xb000, 7, 4, order.Uint32([]byte("0100")))
+	entry(0xb001, 4, 1, 2)
+	entry(0xb002, 7, 32, 50)
+	b = order.AppendUint32(b, 82)
+	for _, e := range [][3]uint32{{0x20030000, primarySize, 0}, {0x00000000, secondarySize, secondaryOffset}} {
+		b = order.AppendUint32(b, e[0])
+		b = order.AppendUint32(b, e[1])
+		b = order.AppendUint32(b, e[2])
+		b = order.AppendUint32(b, 0)
+	}
+	b = order.AppendUint16(b, 1)
+	entry(0xb101, 4, 1, 1)
+	b = order.AppendUint32(b, 0)
+	return segment(0xffe2, b)
+}
+
+// buildMPFAttributes returns the MPF segment of an image other than the
+// primary one, which holds only an MP Attribute IFD
+func buildMPFAttributes(individualNum uint32) []byte {
+	order := binary.LittleEndian
+	b := []byte("MPF\x00II\x2a\x00\x08\x00\x00\x00")
+	b = order.AppendUint16(b, 1)
+	b = order.AppendUint16(b, 0xb101)
+	b = order.AppendUint16(b, 4)
+	b = order.AppendUint32(b, 1)
+	b = order.AppendUint32(b, individualNum)
+	b = order.AppendUint32(b, 0)
+	return segment(0xffe2, b)
+}
+
+func Test_MPF(t *testing.T) {
+	secondary := buildJpeg(
+		segment(0xffe0, []byte{'J', 'F', 'I', 'F', 0, 1, 2, 0, 0, 1, 0, 1, 0, 0}),
+		buildMPFAttributes(2),
+		segment(0xfffe, []byte("depth")),
+	)
+
+	// SOI, the APP2 marker and length, and the identifier precede the header
+	const headerOffset = 10
+	primarySize := uint32(len(buildJpeg(buildMPF(0, 0, 0))))
+	b := append(buildJpeg(buildMPF(primarySize, primarySize-headerOffset, uint32(len(secondary)))), secondary...)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading segments: %s\n", err)
+	}
+	r := ir.(*jfif.Reader)
+
+	mpf := r.MPF()
+	if mpf == nil {
+		t.Fatalf("Expected MPF; got nil")
+	}
+	if mpf.HeaderOffset != headerOffset || len(mpf.Attributes) != 1 {
+		t.Fatalf("Expected header at %d with 1 attribute IFD; got %d with %d", headerOffset, mpf.HeaderOffset, len(mpf.Attributes))
+	}
+	if len(mpf.Images) != 2 {
+		t.Fatalf("Expected 2 images; got %d", len(mpf.Images))
+	}
+	primary := mpf.Images[0]
+	if !primary.Entry.Representative || primary.Entry.Type != tags.BaselinePrimary || primary.Offset != 0 || primary.Entry.Size != primarySize {
+		t.Fatalf("Expected representative primary image at 0 with size %d; got %+v", primarySize, primary)
+	}
+	if mpf.Images[1].Offset != int64(primarySize) {
+		t.Fatalf("Expected secondary image at %d; got %d", primarySize, mpf.Images[1].Offset)
+	}
+	for k, expected := range []uint32{1, 2} {
+		a := mpf.Images[k].Attributes
+		if a == nil {
+			t.Fatalf("Expected attributes for image %d; got nil", k)
+		}
+		if v, ok := a.Get(0xb101); !ok || v.Uint32s()[0] != expected {
+			t.Fatalf("Expected MPIndividualNum %d for image %d; got %v", expected, k, v)
+		}
+	}
+
+	if v, ok := tree.Get(tags.MPIndexIfd, 0xb000); !ok || v.Strings()[0] != "0100" {
+		t.Fatalf("Expected MPFVersion 0100; got %v", v)
+	}
+	if v, ok := tree.Get(tags.MPAttributeIfd, 0xb101); !ok || v.Uint32s()[0] != 1 {
+		t.Fatalf("Expected MPIndividualNum 1; got %v", v)
+	}
+
+	img, err := r.ReadMPImage(1)
+	if err != nil {
+		t.Fatalf("Error while reading MPF image: %s\n", err)
+	}
+	if !bytes.Equal(img, secondary) {
+		t.Fatalf("Expected secondary image %x; got %x", secondary, img)
+	}
+}
+
+func Test_Comments(t *testing.T) {
+	r := readJpeg(t, buildJpeg(
+		segment(0xfffe, []byte("job 1234")),
+		segment(0xffe0, []byte{'J', 'F', 'I', 'F', 0, 1, 2, 0, 0, 1, 0, 1, 0, 0}),
+		segment(0xfffe, []byte("Scann\xe9\x00")),
+		segment(0xfffe, []byte("déjà vu")),
+	))
+
+	expected := []string{"job 1234", "Scanné", "déjà vu"}
+	comments := r.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("Expected %d comments; got %d", len(expected), len(comments))
+	}
+	for k, c := range comments {
+		if c.Text != expected[k] {
+			t.Fatalf("Expected comment %d to be '%s'; got '%s'", k, expected[k], c.Text)
+		}
+	}
+	if !bytes.Equal(comments[1].Raw, []byte("Scann\xe9\x00")) {
+		t.Fatalf("Expected raw comment bytes; got %x", comments[1].Raw)
+	}
+	if r.JFIF() == nil {
+		t.Fatalf("Expected JFIF header between comments; got nil")
+	}
+}
+
+func Test_ImageData(t *testing.T) {
+	// Entropy-coded data spanning several scan chunks, with stuffed 0xff bytes
+	// on either side of a chunk boundary
+	data := bytes.Repeat([]byte{0x12, 0x34, 0xff, 0x00}, 40000)
+	data[64*1024-2], data[64*1024-1], data[64*1024] = 0x56, 0xff, 0x00
+	sos := append(segment(0xffda, []byte{1, 1, 0, 0, 63, 0}), data...)
+	b := buildJpeg(
+		segment(0xfffe, []byte("before")),
+		sos,
+		segment(0xfffe, []byte("after")),
+	)
+
+	var tcs = []struct {
+		name     string
+		opts     []metadata.Option
+		comments int
+		consumed int64
+	}{
+		{"full", nil, 2, int64(len(b) - 2)},
+		{"metadata only", []metadata.Option{metadata.WithMetadataOnly()}, 1, int64(4 + len("before") + 2)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(b), tc.opts...)
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			n, err := ir.ReadPartial(&tags.Tree{})
+			if err != nil {
+				t.Fatalf("Error while reading segments: %s\n", err)
+			}
+			if n != tc.consumed {
+				t.Fatalf("Expected to consume %d bytes; got %d", tc.consumed, n)
+			}
+			if c := ir.(*jfif.Reader).Comments(); len(c) != tc.comments {
+				t.Fatalf("Expected %d comments; got %d", tc.comments, len(c))
+			}
+		})
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	*bytes.Reader
+	read int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.Reader.Read(b)
+	c.read += n
+	return n, err
+}
+
+func Test_RestartMarkers(t *testing.T) {
+	// Short runs of entropy-coded data between many restart markers
+	sos := segment(0xffda, []byte{1, 1, 0, 0, 63, 0})
+	for k := 0; k < 2000; k++ {
+		sos = append(sos, bytes.Repeat([]byte{0x12, 0x34, 0xff, 0x00}, 100)...)
+		sos = append(sos, 0xff, byte(0xd0+k%8))
+	}
+	b := buildJpeg(sos)
+
+	c := &countingReader{Reader: bytes.NewReader(b)}
+	ir, err := metadata.ReadHeader(c)
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err != nil {
+		t.Fatalf("Error while reading segments: %s\n", err)
+	}
+	if n := len(ir.(*jfif.Reader).Segments()); n != 2000+3 {
+		t.Fatalf("Expected %d segments; got %d", 2000+3, n)
+	}
+	if c.read > 2*len(b) {
+		t.Fatalf("Expected to read about %d bytes; read %d", len(b), c.read)
+	}
+}
+
+func Test_Segments(t *testing.T) {
+	jfifSegment := segment(0xffe0, []byte{'J', 'F', 'I', 'F', 0, 1, 2, 0, 0, 1, 0, 1, 0, 0})
+	b := buildJpeg(
+		jfifSegment,
+		segment(0xffe1, []byte("Unknown\x00abc")),
+		[]byte{0xff, 0x01},
+		// Fill bytes before the DRI marker
+		append([]byte{0xff, 0xff}, segment(0xffdd, []byte{0, 0})...),
+		segment(0xffda, []byte{1, 1, 0, 0, 63, 0}),
+		[]byte{0x12, 0xff, 0x00, 0x34},
+		[]byte{0xff, 0xd0},
+		[]byte{0x56, 0x78},
+	)
+	trailer := []byte("PK\x03\x04zipdata")
+	b = append(b, trailer...)
+
+	r := readJpeg(t, b)
+	expected := []jfif.Segment{
+		{Marker: 0xffd8, Offset: 0},
+		{Marker: 0xffe0, Offset: 2, Length: 16, Identifier: "JFIF", Parsed: true},
+		{Marker: 0xffe1, Offset: 20, Length: 13, Identifier: "Unknown"},
+		{Marker: 0xff01, Offset: 35},
+		{Marker: 0xffdd, Offset: 39, Length: 4},
+		{Marker: 0xffda, Offset: 45, Length: 8, DataLength: 4},
+		{Marker: 0xffd0, Offset: 59, DataLength: 2},
+		{Marker: 0xffd9, Offset: 63},
+	}
+	if !reflect.DeepEqual(r.Segments(), expected) {
+		t.Fatalf("Expected segments:\n%+v\ngot:\n%+v", expected, r.Segments())
+	}
+
+	tr := r.Trailer()
+	if tr == nil || tr.Offset != 65 || tr.Size != int64(len(trailer)) || tr.Kind != "zip" {
+		t.Fatalf("Expected zip trailer at 65 with size %d; got %+v", len(trailer), tr)
+	}
+}
+
+// ijgTable scales a standard table as libjpeg does, and encodes it in a DQT
+// table, in zig-zag order
+func ijgTable(id byte, standard [64]int, quality int) []byte {
+	natural := []int{
+		0, 1, 8, 16, 9, 2, 3, 10, 17, 24, 32, 25, 18, 11, 4, 5,
+		12, 19, 26, 33, 40, 48, 41, 34, 27, 20, 13, 6, 7, 14, 21, 28,
+		35, 42, 49, 56, 57, 50, 43, 36, 29, 22, 15, 23, 30, 37, 44, 51,
+		58, 59, 52, 45, 38, 31, 39, 46, 53, 60, 61, 54, 47, 55, 62, 63,
+	}
+	scale := 200 - quality*2
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	b := []byte{id}
+	for _, k := range natural {
+		b = append(b, byte(max(1, min((standard[k]*scale+50)/100, 255))))
+	}
+	return b
+}
+
+func Test_Quality(t *testing.T) {
+	luminance := [64]int{
+		16, 11, 10, 16, 24, 40, 51, 61, 12, 12, 14, 19, 26, 58, 60, 55,
+		14, 13, 16, 24, 40, 57, 69, 56, 14, 17, 22, 29, 51, 87, 80, 62,
+		18, 22, 37, 56, 68, 109, 103, 77, 24, 35, 55, 64, 81, 104, 113, 92,
+		49, 64, 78, 87, 103, 121, 120, 101, 72, 92, 95, 98, 112, 100, 103, 99,
+	}
+	chrominance := [64]int{17, 18, 24, 47, 99, 99, 99, 99, 18, 21, 26, 66, 99, 99, 99, 99, 24, 26, 56, 99, 99, 99, 99, 99, 47, 66, 99}
+	for k := 27; k < 64; k++ {
+		chrominance[k] = 99
+	}
+	sof := segment(0xffc0, []byte{8, 0, 16, 0, 16, 3, 1, 0x22, 0, 2, 0x11, 1, 3, 0x11, 1})
+	adobe := segment(0xffee, []byte{'A', 'd', 'o', 'b', 'e', 0, 100, 0, 0, 0, 0, 1})
+
+	// A custom table, close to quality 90
+	custom := ijgTable(0, luminance, 90)
+	custom[10]++
+
+	var tcs = []struct {
+		name     string
+		segments [][]byte
+		quality  int
+		exact    bool
+	}{
+		{"quality 75", [][]byte{segment(0xffdb, append(ijgTable(0, luminance, 75), ijgTable(1, chrominance, 75)...)), sof}, 75, true},
+		{"quality 30, separate segments", [][]byte{segment(0xffdb, ijgTable(0, luminance, 30)), segment(0xffdb, ijgTable(1, chrominance, 30)), sof}, 30, true},
+		{"quality 100", [][]byte{segment(0xffdb, append(ijgTable(0, luminance, 100), ijgTable(1, chrominance, 100)...)), sof}, 100, true},
+		{"custom, with Adobe segment", [][]byte{adobe, segment(0xffdb, append(custom, ijgTable(1, chrominance, 90)...)), sof}, 90, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := readJpeg(t, buildJpeg(tc.segments...))
+			if len(r.QuantizationTables()) != 2 {
+				t.Fatalf("Expected 2 quantization tables; got %d", len(r.QuantizationTables()))
+			}
+			q := r.EstimateQuality()
+			if q == nil || q.Quality != tc.quality || q.Exact != tc.exact {
+				t.Fatalf("Expected quality %d (exact: %t); got %+v", tc.quality, tc.exact, q)
+			}
+		})
+	}
+
+	if q := readJpeg(t, buildJpeg(sof)).EstimateQuality(); q != nil {
+		t.Fatalf("Expected no estimate without tables; got %+v", q)
+	}
+}
+
+func Test_HuffmanTables(t *testing.T) {
+	// The DC luminance table from Annex K, followed by a small AC table
+	dc := append([]byte{0x00, 0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0}, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+	ac := []byte{0x11, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x02}
+	r := readJpeg(t, buildJpeg(segment(0xffc4, append(dc, ac...))))
+
+	tables := r.HuffmanTables()
+	if len(tables) != 2 {
+		t.Fatalf("Expected 2 Huffman tables; got %d", len(tables))
+	}
+	if tables[0].Class != jfif.DCTable || tables[0].ID != 0 || len(tables[0].Symbols) != 12 {
+		t.Fatalf("Expected DC table 0 with 12 symbols; got %s table %d with %d", tables[0].Class, tables[0].ID, len(tables[0].Symbols))
+	}
+	if tables[1].Class != jfif.ACTable || tables[1].ID != 1 || !bytes.Equal(tables[1].Symbols, []byte{1, 2}) {
+		t.Fatalf("Expected AC table 1 with symbols [1 2]; got %s table %d with %v", tables[1].Class, tables[1].ID, tables[1].Symbols)
+	}
+	if !r.Segments()[1].Parsed {
+		t.Fatalf("Expected DHT segment to be parsed")
+	}
+}
+
+func Test_MalformedSegments(t *testing.T) {
+	sof := segment(0xffc0, []byte{8, 0, 16, 0, 16, 1, 1, 0x11, 0})
+
+	var tcs = []struct {
+		name    string
+		segment []byte
+		repeat  int
+	}{
+		{"XML syntax error", segment(0xffe1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><unclosed")), 1},
+		{"short JFIF thumbnail", segment(0xffe0, []byte{'J', 'F', 'I', 'F', 0, 1, 2, 0, 0, 1, 0, 1, 2, 2}), 1},
+		{"duplicate ICC chunk", iccChunk(1, 2, []byte{1, 2, 3}), 2},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			segments := [][]byte{}
+			for k := 0; k < tc.repeat; k++ {
+				segments = append(segments, tc.segment)
+			}
+			r := readJpeg(t, buildJpeg(append(segments, sof)...))
+
+			if f := r.Frame(); f == nil || f.Width != 16 || f.Height != 16 {
+				t.Fatalf("Expected 16x16 frame after the malformed segment; got %+v", f)
+			}
+			// The last APPn segment precedes the SOF and EOI segments
+			read := r.Segments()
+			last := read[len(read)-3]
+			if last.Marker&0xfff0 != 0xffe0 || last.Parsed {
+				t.Fatalf("Expected unparsed APPn segment; got %+v", last)
+			}
+		})
+	}
+}
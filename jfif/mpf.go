@@ -0,0 +1,204 @@
+package jfif
+
+import (
+	"bytes"
+	"fmt"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+const mpfIdentifier = "MPF"
+
+// MPImage locates one image of a Multi-Picture Format file
+type MPImage struct {
+	Entry tags.MPEntry
+
+	// Offset is the position of the image in the stream, relative to the SOI
+	// of the primary image
+	Offset int64
+
+	// Attributes is the MP Attribute IFD of the image; for the primary image
+	// it follows the MP Index IFD, and for the others it is read from the MPF
+	// segment of the image itself.  It is nil if the image has none.
+	Attributes *tags.Ifd
+}
+
+// MPF is the content of the Multi-Picture Format APP2 segment, used for
+// depth maps, gain maps and stereo pairs
+type MPF struct {
+	// HeaderOffset is the position of the MPF TIFF header in the stream; the
+	// offsets of MP entries are relative to it
+	HeaderOffset int64
+
+	// Index is the MP Index IFD
+	Index *tags.Ifd
+
+	// Attributes holds the MP Attribute IFDs which follow the index
+	Attributes []*tags.Ifd
+
+	// Images lists the images from the MPEntry tag, in index order
+	Images []MPImage
+}
+
+// MPF returns the Multi-Picture Format index of the primary image, or nil if
+// the stream has none
+func (r *Reader) MPF() *MPF {
+	return r.mpf
+}
+
+// ReadMPImage returns the bytes of the image at the provided index of MPF
+// Images
+func (r *Reader) ReadMPImage(k int) ([]byte, error) {
+	if r.mpf == nil || k < 0 || k >= len(r.mpf.Images) {
+		return nil, fmt.Errorf("No MPF image %d", k)
+	}
+	img := r.mpf.Images[k]
+	size, err := r.r.GetSize()
+	if err != nil {
+		return nil, err
+	}
+	if img.Offset+int64(img.Entry.Size) > size {
+		return nil, fmt.Errorf("%w: MPF image %d at offset %d with size %d", common.ErrTruncatedSegment, k, img.Offset, img.Entry.Size)
+	}
+	if err := r.r.SeekTo(img.Offset); err != nil {
+		return nil, err
+	}
+	return r.r.ReadBytes(int(img.Entry.Size))
+}
+
+// readMPFSegment walks the TIFF structure of the MPF segment, which starts at
+// the provided offset in the stream
+func (r *Reader) readMPFSegment(b []byte, offset int64, tree *tags.Tree) error {
+	if r.mpf != nil {
+		r.options.Logger.Debug("Ignoring additional MPF segment")
+		return nil
+	}
+
+	chain, err := r.readMPFIfds(b, tags.MPIndexIfd)
+	tree.Ifds = append(tree.Ifds, chain...)
+	if err != nil {
+		return fmt.Errorf("Failed to read MPF segment: %w", err)
+	}
+
+	mpf := &MPF{HeaderOffset: offset, Index: chain[0], Attributes: chain[1:]}
+	if t, ok := mpf.Index.Get(tags.MPEntryID); ok {
+		if entries, ok := t.(*tags.MPEntryTag); ok {
+			for _, e := range entries.Entries() {
+				img := MPImage{Entry: e}
+				if e.Offset != 0 {
+					img.Offset = offset + int64(e.Offset)
+				}
+				mpf.Images = append(mpf.Images, img)
+			}
+		}
+	}
+	if len(mpf.Images) != 0 && len(mpf.Attributes) != 0 {
+		mpf.Images[0].Attributes = mpf.Attributes[0]
+	}
+	r.options.Logger.Debug("Read MPF segment", "images", len(mpf.Images), "attributes", len(mpf.Attributes))
+	r.mpf = mpf
+	return nil
+}
+
+// readMPFIfds reads the IFD chain of the TIFF structure in an MPF segment,
+// starting with an IFD of the provided ID
+func (r *Reader) readMPFIfds(b []byte, id tags.IfdID) ([]*tags.Ifd, error) {
+	r1, err := metadata.ReadHeader(bytes.NewReader(b), metadata.WithOptions(r.options))
+	if err != nil {
+		return nil, err
+	}
+	tr, ok := r1.(tags.TagReader)
+	if !ok {
+		return nil, fmt.Errorf("%w: MPF segment is not a TIFF structure", common.ErrUnknownFormat)
+	}
+	address, err := tr.GetReader().ReadUint32()
+	if err != nil {
+		return nil, err
+	}
+	return tr.ReadIfd(address, id, []*map[uint16]tags.TagBuilder{&tags.MPFTagMap})
+}
+
+// resolveMPF reads the MP Attribute IFD of each image after the primary one,
+// and then returns to the current position.  An image whose attributes can't
+// be read is logged and left without them.
+func (r *Reader) resolveMPF() error {
+	if r.mpf == nil {
+		return nil
+	}
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return err
+	}
+	for k := range r.mpf.Images {
+		img := &r.mpf.Images[k]
+		if k == 0 || img.Offset == 0 {
+			continue
+		}
+		if img.Attributes, err = r.readMPAttributes(*img); err != nil {
+			r.options.Logger.Debug("Ignoring MPF image attributes", "image", k, "offset", img.Offset, "err", err)
+		}
+	}
+	return r.r.SeekTo(cur)
+}
+
+// readMPAttributes finds the MPF segment among the segments which precede the
+// image data of an MPF image, and reads its MP Attribute IFD.  The MPF segment
+// of an image other than the primary one has no MP Index IFD, so its first
+// IFD is the MP Attribute IFD.
+func (r *Reader) readMPAttributes(img MPImage) (*tags.Ifd, error) {
+	size, err := r.r.GetSize()
+	if err != nil {
+		return nil, err
+	}
+	end := img.Offset + int64(img.Entry.Size)
+	if end > size {
+		return nil, fmt.Errorf("%w: MPF image at offset %d with size %d", common.ErrTruncatedSegment, img.Offset, img.Entry.Size)
+	}
+	if err := r.r.SeekTo(img.Offset); err != nil {
+		return nil, err
+	}
+	m, err := r.readMarker()
+	if err != nil {
+		return nil, err
+	}
+	if marker(m) != soi {
+		return nil, fmt.Errorf("%w: MPF image at offset %d starts with 0x%04x", common.ErrBadMarker, img.Offset, m)
+	}
+
+	for {
+		if m, err = r.readMarker(); err != nil {
+			return nil, err
+		}
+		if marker(m) == sos || marker(m) == eoi {
+			return nil, fmt.Errorf("No MPF segment in image at offset %d", img.Offset)
+		}
+		if m != app2 {
+			if err := r.moveToNextSegment(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		payload, err := r.readSegmentPayload()
+		if err != nil {
+			return nil, err
+		}
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return nil, err
+		}
+		if cur > end {
+			return nil, fmt.Errorf("%w: segment at offset %d runs past the end of the MPF image", common.ErrTruncatedSegment, cur-int64(len(payload))-4)
+		}
+		if identifier(payload) != mpfIdentifier {
+			continue
+		}
+		chain, err := r.readMPFIfds(payload[len(mpfIdentifier)+1:], tags.MPAttributeIfd)
+		if err != nil {
+			return nil, err
+		}
+		return chain[0], nil
+	}
+}
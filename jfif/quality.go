@@ -0,0 +1,106 @@
+package jfif
+
+// The example tables from Annex K of ITU-T T.81, which the IJG libjpeg scales
+// by its quality factor, in row-major order
+var (
+	standardLuminance = [64]uint16{
+		16, 11, 10, 16, 24, 40, 51, 61,
+		12, 12, 14, 19, 26, 58, 60, 55,
+		14, 13, 16, 24, 40, 57, 69, 56,
+		14, 17, 22, 29, 51, 87, 80, 62,
+		18, 22, 37, 56, 68, 109, 103, 77,
+		24, 35, 55, 64, 81, 104, 113, 92,
+		49, 64, 78, 87, 103, 121, 120, 101,
+		72, 92, 95, 98, 112, 100, 103, 99,
+	}
+	standardChrominance = [64]uint16{
+		17, 18, 24, 47, 99, 99, 99, 99,
+		18, 21, 26, 66, 99, 99, 99, 99,
+		24, 26, 56, 99, 99, 99, 99, 99,
+		47, 66, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	}
+)
+
+// QualityEstimate is an approximation of the IJG quality factor an image was
+// encoded with
+type QualityEstimate struct {
+	// Quality is the IJG quality factor, from 1 to 100, whose tables are
+	// closest to those of the image
+	Quality int
+
+	// Exact is true if the tables are exactly the IJG tables for Quality, as
+	// written by libjpeg and the many encoders built on it.  No other encoder
+	// is recognized.
+	Exact bool
+}
+
+// EstimateQuality compares the luminance and chrominance tables with the
+// scaled IJG tables, or returns nil if there are no quantization tables.  Only
+// libjpeg tables are detected, through Exact; for an encoder with its own
+// tables, such as most cameras, the quality is only an approximation, but a
+// higher quality still means finer quantization.
+func (r *Reader) EstimateQuality() *QualityEstimate {
+	lumID, chromID := uint8(0), uint8(1)
+	if r.frame != nil && len(r.frame.Components) != 0 {
+		lumID = r.frame.Components[0].QuantizationTable
+		if len(r.frame.Components) > 1 {
+			chromID = r.frame.Components[1].QuantizationTable
+		}
+	}
+	lum := r.quantizationTable(lumID)
+	if lum == nil {
+		return nil
+	}
+	chrom := r.quantizationTable(chromID)
+	if chrom == lum || (r.frame != nil && len(r.frame.Components) == 1) {
+		chrom = nil
+	}
+
+	best := &QualityEstimate{}
+	bestDiff := -1
+	for q := 100; q >= 1; q-- {
+		diff := tableDifference(lum, &standardLuminance, q)
+		if chrom != nil {
+			diff += tableDifference(chrom, &standardChrominance, q)
+		}
+		if bestDiff < 0 || diff < bestDiff {
+			best.Quality, bestDiff = q, diff
+		}
+	}
+
+	best.Exact = bestDiff == 0
+	return best
+}
+
+// tableDifference sums the absolute differences between a table and the
+// standard table scaled to the provided quality
+func tableDifference(t *QuantizationTable, standard *[64]uint16, quality int) int {
+	limit := 255
+	if t.Precision == 16 {
+		limit = 32767
+	}
+	diff := 0
+	for k, v := range standard {
+		d := scaleValue(v, quality, limit) - int(t.Values[k])
+		if d < 0 {
+			d = -d
+		}
+		diff += d
+	}
+	return diff
+}
+
+// scaleValue scales a standard table value as jpeg_quality_scaling and
+// jpeg_add_quant_table do
+func scaleValue(v uint16, quality, limit int) int {
+	scale := 200 - quality*2
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	s := (int(v)*scale + 50) / 100
+	return max(1, min(s, limit))
+}
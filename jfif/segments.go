@@ -0,0 +1,136 @@
+package jfif
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Segment describes a single marker in the stream
+type Segment struct {
+	Marker uint16
+
+	// Offset is the position of the marker, after any fill bytes
+	Offset int64
+
+	// Length is the value of the length field which follows the marker, or 0
+	// for standalone markers such as SOI, RSTn and EOI
+	Length int64
+
+	// DataLength is the length of the entropy-coded data which follows an SOS
+	// or RSTn segment
+	DataLength int64
+
+	// Identifier is the NUL-terminated string which starts an APPn segment
+	Identifier string
+
+	// Parsed is true if the content of the segment was recognized and read
+	Parsed bool
+}
+
+// Segments returns every marker read, in stream order
+func (r *Reader) Segments() []Segment {
+	return r.segments
+}
+
+// Trailer describes data appended after the EOI marker
+type Trailer struct {
+	// Offset is the position of the first byte after EOI
+	Offset int64
+
+	// Size is the number of bytes after EOI
+	Size int64
+
+	// Kind is a best guess at the content, such as "jpeg", "mp4", "zip" or
+	// "samsung", or an empty string if it is not recognized
+	Kind string
+}
+
+// Trailer returns the data after the EOI marker, or nil if there is none
+func (r *Reader) Trailer() *Trailer {
+	return r.trailer
+}
+
+// readMarker reads the next marker, skipping any 0xff fill bytes which
+// precede it
+func (r *Reader) readMarker() (uint16, error) {
+	b, err := r.r.ReadUint8()
+	if err != nil {
+		return 0, err
+	}
+	if b != 0xff {
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: 0x%02x at offset %d", common.ErrBadMarker, b, cur-1)
+	}
+
+	for fill := 0; ; fill++ {
+		b, err = r.r.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+		if b == 0xff {
+			continue
+		}
+		if b == 0x00 {
+			cur, err := r.r.GetCurrentOffset()
+			if err != nil {
+				return 0, err
+			}
+			return 0, fmt.Errorf("%w: 0xff00 at offset %d", common.ErrBadMarker, cur-2)
+		}
+		if fill != 0 {
+			r.options.Logger.Debug("Skipped fill bytes", "count", fill)
+		}
+		return 0xff00 | uint16(b), nil
+	}
+}
+
+// readTrailer checks for data after the EOI marker, such as an appended MPF
+// image, motion photo video, or vendor trailer, and leaves the reader
+// positioned after EOI.
+func (r *Reader) readTrailer() error {
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return err
+	}
+	if size <= cur {
+		return nil
+	}
+
+	t := &Trailer{Offset: cur, Size: size - cur}
+	head, err := r.r.ReadBytes(int(min(t.Size, 12)))
+	if err != nil {
+		return err
+	}
+	tail := head
+	if t.Size > 12 {
+		if err = r.r.SeekTo(size - 4); err != nil {
+			return err
+		}
+		if tail, err = r.r.ReadBytes(4); err != nil {
+			return err
+		}
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte{0xff, 0xd8, 0xff}):
+		t.Kind = "jpeg"
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		t.Kind = "mp4"
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		t.Kind = "zip"
+	case bytes.HasSuffix(tail, []byte("SEFT")):
+		t.Kind = "samsung"
+	}
+
+	r.options.Logger.Debug("Found trailing data", "offset", t.Offset, "size", t.Size, "kind", t.Kind)
+	r.trailer = t
+	return r.r.SeekTo(cur)
+}
@@ -0,0 +1,188 @@
+package jfif
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// CodingProcess is the DCT or lossless process named by a SOFn marker
+type CodingProcess int
+
+const (
+	// Baseline is the baseline sequential DCT process
+	Baseline CodingProcess = iota
+
+	// ExtendedSequential is the extended sequential DCT process
+	ExtendedSequential
+
+	// Progressive is the progressive DCT process
+	Progressive
+
+	// Lossless is the lossless (predictive) process
+	Lossless
+)
+
+var codingProcesses = [...]string{
+	"baseline",
+	"extended sequential",
+	"progressive",
+	"lossless",
+}
+
+func (p CodingProcess) String() string {
+	return codingProcesses[p]
+}
+
+// Component describes a single color component of a frame
+type Component struct {
+	// ID is the component identifier, referred to by scans
+	ID uint8
+
+	// HorizontalSampling is the horizontal sampling factor, from 1 to 4
+	HorizontalSampling uint8
+
+	// VerticalSampling is the vertical sampling factor, from 1 to 4
+	VerticalSampling uint8
+
+	// QuantizationTable is the ID of the quantization table the component uses
+	QuantizationTable uint8
+}
+
+// Frame describes the encoded image, as read from a SOFn segment.  Unlike the
+// Exif PixelXDimension and PixelYDimension tags, it cannot fall out of step
+// with the image data.
+type Frame struct {
+	// Marker is the SOFn marker the frame was read from
+	Marker uint16
+
+	// Process is the coding process
+	Process CodingProcess
+
+	// Arithmetic is true if the frame uses arithmetic rather than Huffman
+	// entropy coding
+	Arithmetic bool
+
+	// Differential is true if the frame is a differential frame of a
+	// hierarchical image
+	Differential bool
+
+	// Precision is the number of bits per sample
+	Precision uint8
+
+	// Height is the number of lines.  It may be 0, in which case the height is
+	// defined by a DNL segment following the first scan.
+	Height uint16
+
+	// Width is the number of samples per line
+	Width uint16
+
+	// Components lists the color components, in frame order
+	Components []Component
+}
+
+// ChromaSubsampling returns the J:a:b notation (such as "4:2:0") for a three
+// component frame whose chroma components share sampling factors, or an empty
+// string otherwise.
+func (f *Frame) ChromaSubsampling() string {
+	if len(f.Components) != 3 {
+		return ""
+	}
+	y, cb, cr := f.Components[0], f.Components[1], f.Components[2]
+	if cb.HorizontalSampling != cr.HorizontalSampling || cb.VerticalSampling != cr.VerticalSampling {
+		return ""
+	}
+	if cb.HorizontalSampling == 0 || cb.VerticalSampling == 0 || y.HorizontalSampling%cb.HorizontalSampling != 0 || y.VerticalSampling%cb.VerticalSampling != 0 {
+		return ""
+	}
+
+	h := y.HorizontalSampling / cb.HorizontalSampling
+	v := y.VerticalSampling / cb.VerticalSampling
+	a := 4 / int(h)
+	if 4%int(h) != 0 {
+		return ""
+	}
+	b := a
+	if v == 2 {
+		b = 0
+	} else if v != 1 {
+		return ""
+	}
+	return fmt.Sprintf("4:%d:%d", a, b)
+}
+
+// isSOF reports whether the marker is one of the SOFn markers; 0xffc4 (DHT),
+// 0xffc8 (JPG) and 0xffcc (DAC) share the range but are not frames.
+func isSOF(m uint16) bool {
+	return m >= sof0 && m <= sof15 && m != dht && m != jpg && m != dac
+}
+
+// Frame returns the frame read from the first SOFn segment, or nil if no
+// SOFn segment has been read.
+func (r *Reader) Frame() *Frame {
+	return r.frame
+}
+
+func (r *Reader) readSOFSegment(m uint16) error {
+	end, err := r.readSegmentEnd()
+	if err != nil {
+		return err
+	}
+
+	f := &Frame{Marker: m}
+	n := m - sof0
+	f.Differential = n&0x04 != 0
+	f.Arithmetic = n&0x08 != 0
+	switch n & 0x03 {
+	case 0:
+		// Only SOF0 has this pattern; SOF4, SOF8 and SOF12 are not frames
+		f.Process = Baseline
+	case 1:
+		f.Process = ExtendedSequential
+	case 2:
+		f.Process = Progressive
+	case 3:
+		f.Process = Lossless
+	}
+
+	if f.Precision, err = r.r.ReadUint8(); err != nil {
+		return err
+	}
+	if f.Height, err = r.r.ReadUint16(); err != nil {
+		return err
+	}
+	if f.Width, err = r.r.ReadUint16(); err != nil {
+		return err
+	}
+	count, err := r.r.ReadUint8()
+	if err != nil {
+		return err
+	}
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return err
+	}
+	if cur+3*int64(count) > end {
+		return fmt.Errorf("%w: SOF segment is too short for %d components", common.ErrTruncatedSegment, count)
+	}
+
+	f.Components = make([]Component, count)
+	for i := range f.Components {
+		b, err := r.r.ReadBytes(3)
+		if err != nil {
+			return err
+		}
+		f.Components[i] = Component{
+			ID:                 b[0],
+			HorizontalSampling: b[1] >> 4,
+			VerticalSampling:   b[1] & 0x0f,
+			QuantizationTable:  b[2],
+		}
+	}
+
+	r.options.Logger.Debug("Read frame", "process", f.Process, "arithmetic", f.Arithmetic, "width", f.Width, "height", f.Height, "components", count)
+	if r.frame == nil {
+		r.frame = f
+	}
+	return r.r.SeekTo(end)
+}
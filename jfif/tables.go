@@ -0,0 +1,153 @@
+package jfif
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// zigzag maps the position of a coefficient in a DQT segment to its position
+// in the 8x8 block, in row-major order
+var zigzag = [64]uint8{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// QuantizationTable is a table read from a DQT segment
+type QuantizationTable struct {
+	// ID is the destination identifier, from 0 to 3, referred to by frame
+	// components
+	ID uint8
+
+	// Precision is 8 or 16 bits per value
+	Precision uint8
+
+	// Values holds the quantization values of the 8x8 block in row-major
+	// order, rather than the zig-zag order of the segment
+	Values [64]uint16
+}
+
+// HuffmanClass is the class of a Huffman table
+type HuffmanClass uint8
+
+const (
+	// DCTable codes DC coefficients, or lossless differences
+	DCTable HuffmanClass = iota
+
+	// ACTable codes AC coefficients
+	ACTable
+)
+
+var huffmanClasses = [...]string{
+	"DC",
+	"AC",
+}
+
+func (c HuffmanClass) String() string {
+	if int(c) >= len(huffmanClasses) {
+		return "unknown"
+	}
+	return huffmanClasses[c]
+}
+
+// HuffmanTable is the descriptor of a table read from a DHT segment
+type HuffmanTable struct {
+	Class HuffmanClass
+
+	// ID is the destination identifier, from 0 to 3, referred to by scans
+	ID uint8
+
+	// Counts holds the number of codes of each length, from 1 to 16 bits
+	Counts [16]uint8
+
+	// Symbols holds the values associated with the codes, in order of
+	// increasing code length
+	Symbols []uint8
+}
+
+// QuantizationTables returns the tables from every DQT segment, in stream
+// order.  A table may be redefined by a later segment with the same ID.
+func (r *Reader) QuantizationTables() []QuantizationTable {
+	return r.quantizationTables
+}
+
+// HuffmanTables returns the tables from every DHT segment, in stream order
+func (r *Reader) HuffmanTables() []HuffmanTable {
+	return r.huffmanTables
+}
+
+// quantizationTable returns the latest definition of the table with the
+// provided ID
+func (r *Reader) quantizationTable(id uint8) *QuantizationTable {
+	for k := len(r.quantizationTables) - 1; k >= 0; k-- {
+		if r.quantizationTables[k].ID == id {
+			return &r.quantizationTables[k]
+		}
+	}
+	return nil
+}
+
+// readDQTSegment reads a DQT segment, which may define several tables
+func (r *Reader) readDQTSegment() error {
+	b, err := r.readSegmentPayload()
+	if err != nil {
+		return err
+	}
+
+	for len(b) != 0 {
+		q := QuantizationTable{ID: b[0] & 0x0f, Precision: 8}
+		size := 64
+		if b[0]>>4 != 0 {
+			q.Precision = 16
+			size = 128
+		}
+		if len(b) < 1+size {
+			return fmt.Errorf("%w: DQT table %d needs %d bytes; has %d", common.ErrTruncatedSegment, q.ID, size, len(b)-1)
+		}
+		for k := range zigzag {
+			if q.Precision == 8 {
+				q.Values[zigzag[k]] = uint16(b[1+k])
+			} else {
+				q.Values[zigzag[k]] = uint16(b[1+2*k])<<8 | uint16(b[2+2*k])
+			}
+		}
+		r.options.Logger.Debug("Read quantization table", "id", q.ID, "precision", q.Precision)
+		r.quantizationTables = append(r.quantizationTables, q)
+		b = b[1+size:]
+	}
+	return nil
+}
+
+// readDHTSegment reads a DHT segment, which may define several tables
+func (r *Reader) readDHTSegment() error {
+	b, err := r.readSegmentPayload()
+	if err != nil {
+		return err
+	}
+
+	for len(b) != 0 {
+		if len(b) < 17 {
+			return fmt.Errorf("%w: DHT table header is %d bytes", common.ErrTruncatedSegment, len(b))
+		}
+		h := HuffmanTable{Class: HuffmanClass(b[0] >> 4), ID: b[0] & 0x0f}
+		total := 0
+		for k := range h.Counts {
+			h.Counts[k] = b[1+k]
+			total += int(h.Counts[k])
+		}
+		if len(b) < 17+total {
+			return fmt.Errorf("%w: DHT table %s %d needs %d symbols; has %d", common.ErrTruncatedSegment, h.Class, h.ID, total, len(b)-17)
+		}
+		h.Symbols = b[17 : 17+total]
+		r.options.Logger.Debug("Read Huffman table", "class", h.Class, "id", h.ID, "symbols", total)
+		r.huffmanTables = append(r.huffmanTables, h)
+		b = b[17+total:]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package jfif
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/xmp"
+)
+
+const (
+	xmpIdentifier         = "http://ns.adobe.com/xap/1.0/"
+	extendedXmpIdentifier = "http://ns.adobe.com/xmp/extension/"
+)
+
+// XMP returns the XMP packet, including the properties of any Extended XMP,
+// or nil if the stream has no XMP
+func (r *Reader) XMP() *xmp.Packet {
+	return r.xmp
+}
+
+func (r *Reader) readXMPSegment(b []byte) error {
+	if r.xmp != nil {
+		r.options.Logger.Debug("Ignoring additional XMP packet")
+		return nil
+	}
+	p, err := xmp.Parse(b)
+	if err != nil {
+		return fmt.Errorf("Failed to read XMP segment: %w", err)
+	}
+	r.xmp = p
+	return nil
+}
+
+func (r *Reader) readExtendedXMPSegment(b []byte) error {
+	if err := r.extendedXmp.AddChunk(b); err != nil {
+		return fmt.Errorf("Failed to read Extended XMP segment: %w", err)
+	}
+	return nil
+}
+
+// resolveExtendedXMP merges the Extended XMP into the main packet, once all
+// its chunks have been read.  An incomplete or corrupt extended packet is
+// dropped, leaving the main packet intact.
+func (r *Reader) resolveExtendedXMP() {
+	if r.xmp == nil {
+		return
+	}
+	if err := r.extendedXmp.Resolve(r.xmp); err != nil {
+		r.options.Logger.Debug("Ignoring Extended XMP", "err", err)
+	}
+}
@@ -1,8 +1,6 @@
 package metadata
 
 import (
-	"errors"
-	"fmt"
 	"io"
 )
 
@@ -16,7 +14,8 @@ func RegisterHeaderCheck(fn CheckHeader) {
 
 // ReadHeader loops over the collection of HeaderCheck methods to determine
 // which ImageReader implementation to use
-func ReadHeader(reader io.ReadSeeker) (ImageReader, error) {
+func ReadHeader(reader io.ReadSeeker, opts ...Option) (ImageReader, error) {
+	options := newOptions(opts)
 	start, err := reader.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return nil, err
@@ -26,16 +25,16 @@ func ReadHeader(reader io.ReadSeeker) (ImageReader, error) {
 		if err != nil {
 			return nil, err
 		}
-		r, err := f(reader)
+		r, err := f(reader, options)
 		if err != nil {
-			fmt.Printf("Err: %s\n", err)
+			options.Logger.Debug("Failed to check header", "offset", start, "error", err)
 			return nil, err
 		}
 		if r != nil {
-			fmt.Printf("OK\n")
 			return r, nil
 		}
 	}
 
-	return nil, errors.New("Unknown file format")
+	options.Logger.Debug("Unknown file format", "offset", start)
+	return nil, ErrUnknownFormat
 }
@@ -2,13 +2,22 @@ package metadata_test
 
 import (
 	"bytes"
+	"errors"
+	"log/slog"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/bmp"
+	"github.com/object88/go-image-metadata/gif"
+	"github.com/object88/go-image-metadata/heif"
+	"github.com/object88/go-image-metadata/ico"
 	"github.com/object88/go-image-metadata/jfif"
+	"github.com/object88/go-image-metadata/png"
 	"github.com/object88/go-image-metadata/tiff"
+	"github.com/object88/go-image-metadata/webp"
 )
 
 func Test_Header(t *testing.T) {
@@ -19,6 +28,14 @@ func Test_Header(t *testing.T) {
 		expectedReader reflect.Type
 	}{
 		{"JFIF", []byte{0xff, 0xd8}, false, reflect.TypeOf(&jfif.Reader{})},
+		{"PNG", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, false, reflect.TypeOf(&png.Reader{})},
+		{"WebP", []byte("RIFF\x00\x00\x00\x00WEBP"), false, reflect.TypeOf(&webp.Reader{})},
+		{"BMP", []byte("BM\x00\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00\x28\x00\x00\x00"), false, reflect.TypeOf(&bmp.Reader{})},
+		{"ICO", []byte{0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x10, 0x10, 0x00, 0x00, 0x01, 0x00, 0x20, 0x00, 0x01, 0x00, 0x00, 0x00, 0x16, 0x00, 0x00, 0x00, 0x00}, false, reflect.TypeOf(&ico.Reader{})},
+		{"CUR", []byte{0x00, 0x00, 0x02, 0x00, 0x01, 0x00, 0x10, 0x10, 0x00, 0x00, 0x01, 0x00, 0x20, 0x00, 0x01, 0x00, 0x00, 0x00, 0x16, 0x00, 0x00, 0x00, 0x00}, false, reflect.TypeOf(&ico.Reader{})},
+		{"GIF", []byte("GIF89a"), false, reflect.TypeOf(&gif.Reader{})},
+		{"HEIC", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), false, reflect.TypeOf(&heif.Reader{})},
+		{"AVIF", []byte("\x00\x00\x00\x14ftypavif\x00\x00\x00\x00mif1"), false, reflect.TypeOf(&heif.Reader{})},
 		{"Motorola TIFF", []byte{0x4d, 0x4d, 0x00, 0x2a}, false, reflect.TypeOf(&tiff.MotorolaReader{})},
 		{"Intel TIFF", []byte{0x49, 0x49, 0x2a, 0x00}, false, reflect.TypeOf(&tiff.IntelReader{})},
 		{"bogus Intell TIFF", []byte{0x49, 0x49, 0x01, 0x01}, true, nil},
@@ -73,11 +90,65 @@ func Test_File(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error while reading header: %s\n", err)
 	}
-	m := ir.Read()
+	m, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading tags: %s\n", err)
+	}
 	if m == nil {
 		t.Fatalf("Failed to return map from Read method")
 	}
-	if len(m) == 0 {
+	if len(m.Ifds) == 0 {
 		t.Fatalf("Failed to return populated map from Read method")
 	}
 }
+
+func Test_Truncated(t *testing.T) {
+	var tcs = []struct {
+		name        string
+		data        []byte
+		expectedErr error
+	}{
+		{"JFIF without EOI", []byte{0xff, 0xd8, 0xff, 0xdb, 0x00, 0x04, 0x00, 0x00}, metadata.ErrTruncatedSegment},
+		{"JFIF short segment", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x10, 'E', 'x'}, metadata.ErrTruncatedSegment},
+		{"JFIF bad marker", []byte{0xff, 0xd8, 0x12, 0x34}, metadata.ErrBadMarker},
+		{"JFIF bad Exif", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x0c, 'E', 'x', 'i', 'f', 0x00, 0x00, 0x01, 0x02, 0x03, 0x04}, metadata.ErrUnknownFormat},
+		{"TIFF without IFD", []byte{0x49, 0x49, 0x2a, 0x00, 0x08, 0x00}, metadata.ErrTruncatedSegment},
+		{"TIFF bad IFD offset", []byte{0x4d, 0x4d, 0x00, 0x2a, 0x00, 0x00, 0x10, 0x00}, metadata.ErrBadIfdOffset},
+		{"TIFF IFD loop", []byte{0x49, 0x49, 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00}, metadata.ErrBadIfdOffset},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			_, err = ir.Read()
+			if err == nil {
+				t.Fatalf("Expected error reading tags; no error returned\n")
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error '%s'; got '%s'", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func Test_Logger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	data := []byte{0xff, 0xd8, 0xff, 0xd9}
+	ir, err := metadata.ReadHeader(bytes.NewReader(data), metadata.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	_, err = ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading tags: %s\n", err)
+	}
+
+	if !strings.Contains(buffer.String(), "marker=0xffd9") {
+		t.Fatalf("Expected EOI marker to be logged; got '%s'", buffer.String())
+	}
+}
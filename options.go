@@ -0,0 +1,66 @@
+package metadata
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Options configures how a byte stream is read.
+type Options struct {
+	// Logger receives debug output while reading.  It defaults to a logger
+	// which discards everything.
+	Logger *slog.Logger
+
+	// MetadataOnly stops reading at the start of the image data, such as the
+	// first SOS segment of a JPEG, skipping any metadata which follows it.
+	MetadataOnly bool
+
+	// VerifyChecksums checks the stored checksum of each chunk, in formats
+	// such as PNG which have them, and fails with ErrBadChecksum on a
+	// mismatch.
+	VerifyChecksums bool
+}
+
+// Option modifies the Options used by ReadHeader
+type Option func(o *Options)
+
+// WithLogger directs debug output to the provided logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithMetadataOnly stops reading at the start of the image data
+func WithMetadataOnly() Option {
+	return func(o *Options) {
+		o.MetadataOnly = true
+	}
+}
+
+// WithChecksums verifies the checksums of chunks, where the format has them
+func WithChecksums() Option {
+	return func(o *Options) {
+		o.VerifyChecksums = true
+	}
+}
+
+// WithOptions replaces all options with a copy of the provided Options.  It
+// is used by ImageReader implementations which hand a nested byte stream back
+// to ReadHeader.
+func WithOptions(options *Options) Option {
+	return func(o *Options) {
+		*o = *options
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	o := &Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.Logger == nil {
+		o.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return o
+}
@@ -0,0 +1,352 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/xmp"
+)
+
+// maxInflatedSize limits the size of compressed text and profiles, to guard
+// against decompression bombs
+const maxInflatedSize = 16 << 20
+
+const xmpKeyword = "XML:com.adobe.xmp"
+
+// ColorType is the color type from the IHDR chunk
+type ColorType uint8
+
+const (
+	// Grayscale has a single gray sample per pixel
+	Grayscale ColorType = 0
+
+	// TrueColor has red, green and blue samples per pixel
+	TrueColor ColorType = 2
+
+	// Indexed has a palette index per pixel
+	Indexed ColorType = 3
+
+	// GrayscaleAlpha has gray and alpha samples per pixel
+	GrayscaleAlpha ColorType = 4
+
+	// TrueColorAlpha has red, green, blue and alpha samples per pixel
+	TrueColorAlpha ColorType = 6
+)
+
+var colorTypes = map[ColorType]string{
+	Grayscale:      "grayscale",
+	TrueColor:      "truecolor",
+	Indexed:        "indexed",
+	GrayscaleAlpha: "grayscale with alpha",
+	TrueColorAlpha: "truecolor with alpha",
+}
+
+func (c ColorType) String() string {
+	if s, ok := colorTypes[c]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Header is the content of the IHDR chunk
+type Header struct {
+	Width     uint32
+	Height    uint32
+	BitDepth  uint8
+	ColorType ColorType
+
+	// Compression and Filter are always 0 in a valid PNG
+	Compression uint8
+	Filter      uint8
+
+	// Interlaced is true for Adam7 interlacing
+	Interlaced bool
+}
+
+// Physical is the content of the pHYs chunk
+type Physical struct {
+	X uint32
+	Y uint32
+
+	// PerMeter is true if X and Y are pixels per meter; otherwise they only
+	// specify the pixel aspect ratio
+	PerMeter bool
+}
+
+// DPI converts the pixels per meter to dots per inch
+func (p *Physical) DPI() (float64, float64, bool) {
+	if !p.PerMeter {
+		return 0, 0, false
+	}
+	return float64(p.X) * 0.0254, float64(p.Y) * 0.0254, true
+}
+
+// Chromaticities is the content of the cHRM chunk
+type Chromaticities struct {
+	WhiteX float64
+	WhiteY float64
+	RedX   float64
+	RedY   float64
+	GreenX float64
+	GreenY float64
+	BlueX  float64
+	BlueY  float64
+}
+
+// Text is a keyword and value from a tEXt, zTXt or iTXt chunk
+type Text struct {
+	Keyword string
+	Text    string
+
+	// Compressed is true for zTXt chunks, and compressed iTXt chunks
+	Compressed bool
+
+	// Language and TranslatedKeyword are only set by iTXt chunks
+	Language          string
+	TranslatedKeyword string
+}
+
+// Header returns the IHDR chunk, or nil if there was none
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Physical returns the pHYs chunk, or nil if there was none
+func (r *Reader) Physical() *Physical {
+	return r.physical
+}
+
+// Gamma returns the gamma from the gAMA chunk
+func (r *Reader) Gamma() (float64, bool) {
+	if r.gamma == nil {
+		return 0, false
+	}
+	return *r.gamma, true
+}
+
+// Chromaticities returns the cHRM chunk, or nil if there was none
+func (r *Reader) Chromaticities() *Chromaticities {
+	return r.chromaticities
+}
+
+// SRGB returns the rendering intent from the sRGB chunk; its presence means
+// the image is in the sRGB color space
+func (r *Reader) SRGB() (icc.RenderingIntent, bool) {
+	if r.srgb == nil {
+		return 0, false
+	}
+	return *r.srgb, true
+}
+
+// ICCProfile returns the profile from the iCCP chunk, or nil if there was none
+func (r *Reader) ICCProfile() *icc.Profile {
+	return r.iccProfile
+}
+
+// ICCProfileName returns the name of the profile from the iCCP chunk, or an
+// empty string if there was none
+func (r *Reader) ICCProfileName() string {
+	return r.iccName
+}
+
+// Texts returns the text chunks, in stream order
+func (r *Reader) Texts() []Text {
+	return r.texts
+}
+
+// Text returns the value of the first text chunk with the provided keyword
+func (r *Reader) Text(keyword string) (string, bool) {
+	for _, t := range r.texts {
+		if t.Keyword == keyword {
+			return t.Text, true
+		}
+	}
+	return "", false
+}
+
+// LastModified returns the time from the tIME chunk, in UTC, or nil if there
+// was none
+func (r *Reader) LastModified() *time.Time {
+	return r.modified
+}
+
+// XMP returns the XMP packet from the "XML:com.adobe.xmp" iTXt chunk, or nil
+// if there was none
+func (r *Reader) XMP() *xmp.Packet {
+	return r.xmp
+}
+
+func (r *Reader) readIHDR(data []byte) error {
+	if err := checkLength("IHDR", data, 13); err != nil {
+		return err
+	}
+	r.header = &Header{
+		Width:       be32(data[0:]),
+		Height:      be32(data[4:]),
+		BitDepth:    data[8],
+		ColorType:   ColorType(data[9]),
+		Compression: data[10],
+		Filter:      data[11],
+		Interlaced:  data[12] == 1,
+	}
+	r.options.Logger.Debug("Read header", "width", r.header.Width, "height", r.header.Height, "bitDepth", r.header.BitDepth, "colorType", r.header.ColorType)
+	return nil
+}
+
+func (r *Reader) readPHYs(data []byte) error {
+	if err := checkLength("pHYs", data, 9); err != nil {
+		return err
+	}
+	r.physical = &Physical{X: be32(data[0:]), Y: be32(data[4:]), PerMeter: data[8] == 1}
+	return nil
+}
+
+func (r *Reader) readGAMA(data []byte) error {
+	if err := checkLength("gAMA", data, 4); err != nil {
+		return err
+	}
+	g := float64(be32(data)) / 100000
+	r.gamma = &g
+	return nil
+}
+
+func (r *Reader) readCHRM(data []byte) error {
+	if err := checkLength("cHRM", data, 32); err != nil {
+		return err
+	}
+	v := make([]float64, 8)
+	for k := range v {
+		v[k] = float64(be32(data[k*4:])) / 100000
+	}
+	r.chromaticities = &Chromaticities{v[0], v[1], v[2], v[3], v[4], v[5], v[6], v[7]}
+	return nil
+}
+
+func (r *Reader) readSRGB(data []byte) error {
+	if err := checkLength("sRGB", data, 1); err != nil {
+		return err
+	}
+	intent := icc.RenderingIntent(data[0])
+	r.srgb = &intent
+	return nil
+}
+
+func (r *Reader) readICCP(data []byte) error {
+	name, rest, err := splitKeyword("iCCP", data)
+	if err != nil {
+		return err
+	}
+	if err := checkLength("iCCP", rest, 1); err != nil {
+		return err
+	}
+	b, err := inflate(rest[1:])
+	if err != nil {
+		return fmt.Errorf("Failed to read iCCP chunk: %w", err)
+	}
+	p, err := icc.Parse(b)
+	if err != nil {
+		return fmt.Errorf("Failed to read iCCP chunk: %w", err)
+	}
+	r.iccName, r.iccProfile = name, p
+	return nil
+}
+
+func (r *Reader) readTEXt(data []byte) error {
+	keyword, rest, err := splitKeyword("tEXt", data)
+	if err != nil {
+		return err
+	}
+	r.texts = append(r.texts, Text{Keyword: keyword, Text: common.DecodeLatin1(rest)})
+	return nil
+}
+
+func (r *Reader) readZTXt(data []byte) error {
+	keyword, rest, err := splitKeyword("zTXt", data)
+	if err != nil {
+		return err
+	}
+	if err := checkLength("zTXt", rest, 1); err != nil {
+		return err
+	}
+	b, err := inflate(rest[1:])
+	if err != nil {
+		return fmt.Errorf("Failed to read zTXt chunk '%s': %w", keyword, err)
+	}
+	r.texts = append(r.texts, Text{Keyword: keyword, Text: common.DecodeLatin1(b), Compressed: true})
+	return nil
+}
+
+func (r *Reader) readITXt(data []byte) error {
+	keyword, rest, err := splitKeyword("iTXt", data)
+	if err != nil {
+		return err
+	}
+	if err := checkLength("iTXt", rest, 2); err != nil {
+		return err
+	}
+	t := Text{Keyword: keyword, Compressed: rest[0] == 1}
+	language, rest, err := splitKeyword("iTXt", rest[2:])
+	if err != nil {
+		return err
+	}
+	translated, rest, err := splitKeyword("iTXt", rest)
+	if err != nil {
+		return err
+	}
+	t.Language, t.TranslatedKeyword = language, translated
+
+	if t.Compressed {
+		if rest, err = inflate(rest); err != nil {
+			return fmt.Errorf("Failed to read iTXt chunk '%s': %w", keyword, err)
+		}
+	}
+	t.Text = string(rest)
+
+	if keyword == xmpKeyword && r.xmp == nil {
+		if r.xmp, err = xmp.Parse(rest); err != nil {
+			return fmt.Errorf("Failed to read XMP: %w", err)
+		}
+	}
+	r.texts = append(r.texts, t)
+	return nil
+}
+
+func (r *Reader) readTIME(data []byte) error {
+	if err := checkLength("tIME", data, 7); err != nil {
+		return err
+	}
+	year := int(data[0])<<8 | int(data[1])
+	t := time.Date(year, time.Month(data[2]), int(data[3]), int(data[4]), int(data[5]), int(data[6]), 0, time.UTC)
+	r.modified = &t
+	return nil
+}
+
+// splitKeyword splits a chunk at the first NUL
+func splitKeyword(name string, data []byte) (string, []byte, error) {
+	n := bytes.IndexByte(data, 0)
+	if n < 0 {
+		return "", nil, fmt.Errorf("%w: %s chunk has no NUL separator", common.ErrTruncatedSegment, name)
+	}
+	return common.DecodeLatin1(data[:n]), data[n+1:], nil
+}
+
+func inflate(b []byte) ([]byte, error) {
+	z, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	out, err := io.ReadAll(io.LimitReader(z, maxInflatedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxInflatedSize {
+		return nil, fmt.Errorf("Inflated data exceeds %d bytes", maxInflatedSize)
+	}
+	return out, nil
+}
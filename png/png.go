@@ -0,0 +1,228 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+
+	// eXIf chunks are TIFF structures
+	_ "github.com/object88/go-image-metadata/tiff"
+)
+
+var signature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// maxChunkLength is the largest length allowed by the PNG specification
+const maxChunkLength = 0x7fffffff
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Reader understands a PNG byte stream
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	header         *Header
+	physical       *Physical
+	gamma          *float64
+	chromaticities *Chromaticities
+	srgb           *icc.RenderingIntent
+	iccName        string
+	iccProfile     *icc.Profile
+	texts          []Text
+	modified       *time.Time
+	xmp            *xmp.Packet
+}
+
+// CheckHeader checks the byte stream to see if it starts with the PNG
+// signature
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, len(signature))
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a PNG
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(b, signature) {
+		return nil, nil
+	}
+	options.Logger.Debug("Matched PNG header", "offset", cur)
+	return &Reader{r: reader.CreateBigEndianReader(r, cur, options.Logger), options: options}, nil
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+
+	// Loop over chunks
+	for {
+		length, err := r.r.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		kind, err := r.r.ReadBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return 0, err
+		}
+		name := string(kind)
+		r.options.Logger.Debug("Read chunk", "type", name, "length", length, "offset", cur-8)
+
+		if length > maxChunkLength || cur+int64(length)+4 > size {
+			return 0, fmt.Errorf("%w: %s chunk at offset %d with length %d", common.ErrTruncatedSegment, name, cur-8, length)
+		}
+
+		if name == "IDAT" {
+			if r.options.MetadataOnly {
+				// Most metadata precedes the image data, although eXIf and text
+				// chunks may follow it.
+				r.options.Logger.Debug("Stopping at image data", "offset", cur-8)
+				break
+			}
+			if err = r.skipChunk(kind, cur, length); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		data, err := r.r.ReadBytes(int(length))
+		if err != nil {
+			return 0, err
+		}
+		crc, err := r.r.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		if r.options.VerifyChecksums {
+			if actual := crc32.Update(crc32.ChecksumIEEE(kind), crc32.IEEETable, data); actual != crc {
+				return 0, fmt.Errorf("%w: %s chunk at offset %d has CRC 0x%08x; expected 0x%08x", common.ErrBadChecksum, name, cur-8, crc, actual)
+			}
+		}
+
+		if name == "IEND" {
+			break
+		}
+		if err = r.readChunk(name, data, tree); err != nil {
+			// Ancillary chunks, with a lower-case first letter, are not needed
+			// to read the rest of the stream; only the Exif data of eXIf feeds
+			// the tree being read.
+			if name[0]&0x20 == 0 || name == "eXIf" {
+				return 0, err
+			}
+			r.options.Logger.Debug("Ignoring chunk", "type", name, "offset", cur-8, "err", err)
+		}
+	}
+
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	return cur - start, nil
+}
+
+// skipChunk moves past the data and CRC of a chunk which isn't parsed.  If
+// checksums are verified, the data is streamed through the CRC rather than
+// read into memory.
+func (r *Reader) skipChunk(kind []byte, offset int64, length uint32) error {
+	if !r.options.VerifyChecksums {
+		return r.r.SeekTo(offset + int64(length) + 4)
+	}
+
+	h := crc32.NewIEEE()
+	h.Write(kind)
+	if _, err := io.CopyN(h, r.r.GetReader(), int64(length)); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %s chunk at offset %d", common.ErrTruncatedSegment, kind, offset-8)
+		}
+		return err
+	}
+	crc, err := r.r.ReadUint32()
+	if err != nil {
+		return err
+	}
+	if actual := h.Sum32(); actual != crc {
+		return fmt.Errorf("%w: %s chunk at offset %d has CRC 0x%08x; expected 0x%08x", common.ErrBadChecksum, kind, offset-8, crc, actual)
+	}
+	return nil
+}
+
+func (r *Reader) readChunk(name string, data []byte, tree *tags.Tree) error {
+	switch name {
+	case "IHDR":
+		return r.readIHDR(data)
+	case "pHYs":
+		return r.readPHYs(data)
+	case "gAMA":
+		return r.readGAMA(data)
+	case "cHRM":
+		return r.readCHRM(data)
+	case "sRGB":
+		return r.readSRGB(data)
+	case "iCCP":
+		return r.readICCP(data)
+	case "tEXt":
+		return r.readTEXt(data)
+	case "zTXt":
+		return r.readZTXt(data)
+	case "iTXt":
+		return r.readITXt(data)
+	case "tIME":
+		return r.readTIME(data)
+	case "eXIf":
+		r1, err := metadata.ReadHeader(bytes.NewReader(data), metadata.WithOptions(r.options))
+		if err != nil {
+			return fmt.Errorf("Failed to read eXIf chunk: %w", err)
+		}
+		_, err = r1.ReadPartial(tree)
+		if err != nil {
+			return fmt.Errorf("Failed to read eXIf chunk: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkLength returns an error if a chunk is shorter than its fixed size
+func checkLength(name string, data []byte, size int) error {
+	if len(data) < size {
+		return fmt.Errorf("%w: %s chunk is %d bytes; expected %d", common.ErrTruncatedSegment, name, len(data), size)
+	}
+	return nil
+}
+
+func be32(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b)
+}
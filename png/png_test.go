@@ -0,0 +1,218 @@
+package png_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/png"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+// chunk encodes a chunk, with its length and CRC
+func chunk(kind string, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	b = append(b, kind...)
+	b = append(b, data...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[4:]))
+}
+
+// buildPng adds the signature before the chunks, and IEND after them
+func buildPng(chunks ...[]byte) []byte {
+	b := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	return append(b, chunk("IEND", nil)...)
+}
+
+func compress(b []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+
+func Test_Chunks(t *testing.T) {
+	ihdr := []byte{0, 0, 2, 0x80, 0, 0, 1, 0xe0, 8, 6, 0, 0, 1}
+	phys := []byte{0, 0, 0x0b, 0x13, 0, 0, 0x0b, 0x13, 1}
+	chrm := []byte{}
+	for _, v := range []uint32{31270, 32900, 64000, 33000, 30000, 60000, 15000, 6000} {
+		chrm = binary.BigEndian.AppendUint32(chrm, v)
+	}
+
+	profile := make([]byte, 132)
+	binary.BigEndian.PutUint32(profile, 132)
+	profile[8] = 2
+	copy(profile[12:], "mntrRGB XYZ ")
+	copy(profile[36:], "acsp")
+
+	exif := []byte{'M', 'M', 0, 0x2a, 0, 0, 0, 8, 0, 1, 0x01, 0x00, 0, 3, 0, 0, 0, 1, 0x02, 0x80, 0, 0, 0, 0, 0, 0}
+
+	b := buildPng(
+		chunk("IHDR", ihdr),
+		chunk("pHYs", phys),
+		chunk("gAMA", []byte{0, 0, 0xb1, 0x8f}),
+		chunk("cHRM", chrm),
+		chunk("sRGB", []byte{0}),
+		chunk("iCCP", append([]byte("Display\x00\x00"), compress(profile)...)),
+		chunk("tEXt", []byte("Author\x00Andr\xe9")),
+		chunk("zTXt", append([]byte("Comment\x00\x00"), compress([]byte("compressed"))...)),
+		chunk("iTXt", []byte("Title\x00\x00\x00fr\x00Titre\x00Bonjour à tous")),
+		chunk("tIME", []byte{0x07, 0xe8, 2, 29, 13, 45, 30}),
+		chunk("IDAT", []byte{1, 2, 3}),
+		chunk("eXIf", exif),
+	)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading chunks: %s\n", err)
+	}
+	r := ir.(*png.Reader)
+
+	h := r.Header()
+	if h == nil || h.Width != 640 || h.Height != 480 || h.BitDepth != 8 || h.ColorType != png.TrueColorAlpha || !h.Interlaced {
+		t.Fatalf("Expected 640x480 8 bit interlaced RGBA header; got %+v", h)
+	}
+	if x, y, ok := r.Physical().DPI(); !ok || int(x+0.5) != 72 || int(y+0.5) != 72 {
+		t.Fatalf("Expected 72 DPI; got %f x %f", x, y)
+	}
+	if g, ok := r.Gamma(); !ok || g != 0.45455 {
+		t.Fatalf("Expected gamma 0.45455; got %f", g)
+	}
+	if c := r.Chromaticities(); c == nil || c.WhiteX != 0.3127 || c.BlueY != 0.06 {
+		t.Fatalf("Expected D65 white point and sRGB blue; got %+v", c)
+	}
+	if intent, ok := r.SRGB(); !ok || intent != icc.Perceptual {
+		t.Fatalf("Expected perceptual sRGB; got %s", intent)
+	}
+	if p, name := r.ICCProfile(), r.ICCProfileName(); p == nil || name != "Display" || p.Class != icc.DisplayClass {
+		t.Fatalf("Expected display profile named 'Display'; got '%s'", name)
+	}
+
+	expected := []png.Text{
+		{Keyword: "Author", Text: "André"},
+		{Keyword: "Comment", Text: "compressed", Compressed: true},
+		{Keyword: "Title", Text: "Bonjour à tous", Language: "fr", TranslatedKeyword: "Titre"},
+	}
+	texts := r.Texts()
+	if len(texts) != len(expected) {
+		t.Fatalf("Expected %d texts; got %d", len(expected), len(texts))
+	}
+	for k, text := range texts {
+		if text != expected[k] {
+			t.Fatalf("Expected text %+v; got %+v", expected[k], text)
+		}
+	}
+
+	if m := r.LastModified(); m == nil || !m.Equal(time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC)) {
+		t.Fatalf("Expected modification time 2024-02-29 13:45:30; got %v", m)
+	}
+	if v, ok := tree.Get(tags.Ifd0, 0x0100); !ok || v.Uint32s()[0] != 640 {
+		t.Fatalf("Expected Exif ImageWidth 640; got %v", v)
+	}
+}
+
+func Test_MalformedChunks(t *testing.T) {
+	b := buildPng(
+		chunk("IHDR", []byte{0, 0, 0, 1, 0, 0, 0, 1, 8, 0, 0, 0, 0}),
+		chunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta><unclosed")),
+		chunk("iCCP", append([]byte("Display\x00\x00"), compress([]byte("not a profile"))...)),
+		chunk("tIME", []byte{0x07, 0xe8, 2, 29, 13, 45, 30}),
+	)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	if _, err = ir.Read(); err != nil {
+		t.Fatalf("Expected malformed chunks to be skipped; got '%s'", err)
+	}
+	r := ir.(*png.Reader)
+	if r.XMP() != nil {
+		t.Fatalf("Expected no XMP packet")
+	}
+	if r.ICCProfile() != nil {
+		t.Fatalf("Expected no ICC profile")
+	}
+	if r.LastModified() == nil {
+		t.Fatalf("Expected the chunk after the malformed ones to be read")
+	}
+}
+
+func Test_Checksums(t *testing.T) {
+	badText := chunk("tEXt", []byte("Author\x00someone"))
+	badText[len(badText)-1]++
+	badData := chunk("IDAT", []byte{1, 2, 3})
+	badData[len(badData)-1]++
+
+	var tcs = []struct {
+		name     string
+		chunk    []byte
+		opts     []metadata.Option
+		expected error
+	}{
+		{"ignored", badText, nil, nil},
+		{"verified", badText, []metadata.Option{metadata.WithChecksums()}, metadata.ErrBadChecksum},
+		{"ignored image data", badData, nil, nil},
+		{"verified image data", badData, []metadata.Option{metadata.WithChecksums()}, metadata.ErrBadChecksum},
+		{"valid image data", chunk("IDAT", []byte{1, 2, 3}), []metadata.Option{metadata.WithChecksums()}, nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := buildPng(chunk("IHDR", make([]byte, 13)), tc.chunk)
+			ir, err := metadata.ReadHeader(bytes.NewReader(b), tc.opts...)
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			_, err = ir.Read()
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error %v; got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func Test_MetadataOnly(t *testing.T) {
+	b := buildPng(
+		chunk("IHDR", make([]byte, 13)),
+		chunk("IDAT", []byte{1, 2, 3}),
+		chunk("tEXt", []byte("Author\x00someone")),
+	)
+
+	var tcs = []struct {
+		name  string
+		opts  []metadata.Option
+		texts int
+	}{
+		{"full", nil, 1},
+		{"metadata only", []metadata.Option{metadata.WithMetadataOnly()}, 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(b), tc.opts...)
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			if _, err = ir.Read(); err != nil {
+				t.Fatalf("Error while reading chunks: %s\n", err)
+			}
+			if n := len(ir.(*png.Reader).Texts()); n != tc.texts {
+				t.Fatalf("Expected %d texts; got %d", tc.texts, n)
+			}
+		})
+	}
+}
@@ -1,37 +1,66 @@
 package reader
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+
+	"github.com/object88/go-image-metadata/common"
 )
 
+// scanChunkSize is the number of bytes ReadTo reads at once
+const scanChunkSize = 64 * 1024
+
 type base struct {
 	r      io.ReadSeeker
 	offset int64
+	logger *slog.Logger
+
+	// scan buffers the bytes most recently read by ReadTo, starting at the
+	// absolute offset scanStart
+	scan      []byte
+	scanStart int64
 }
 
 func (r *base) Discard(count int64) error {
 	_, err := r.r.Seek(count, io.SeekCurrent)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	fmt.Printf("moved by %d bytes\n", count)
+	r.logger.Debug("Discarded bytes", "count", count)
 	return nil
 }
 
-func (r *base) GetCurrentOffset() int64 {
+func (r *base) GetCurrentOffset() (int64, error) {
 	cur, err := r.r.Seek(0, io.SeekCurrent)
 	if err != nil {
-		panic("AT THE DISCO")
+		return 0, err
 	}
-	return cur - r.offset
+	return cur - r.offset, nil
 }
 
 func (r *base) GetReader() io.ReadSeeker {
 	return r.r
 }
 
+func (r *base) GetSize() (int64, error) {
+	cur, err := r.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := r.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.r.Seek(cur, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	return end - r.offset, nil
+}
+
 func (r *base) ReadNullTerminatedString() (string, error) {
 	start, err := r.r.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -40,13 +69,10 @@ func (r *base) ReadNullTerminatedString() (string, error) {
 	b := []byte{0x00}
 	length := 0
 	for {
-		n, e := r.r.Read(b)
+		_, e := readFull(r.r, b)
 		if e != nil {
 			return "", e
 		}
-		if n != 1 {
-			return "", errors.New("Not enough byte")
-		}
 		if b[0] == '\x00' {
 			// This is the end.
 			break
@@ -54,45 +80,105 @@ func (r *base) ReadNullTerminatedString() (string, error) {
 		length++
 	}
 
-	r.r.Seek(start, io.SeekStart)
-	buf := make([]byte, length)
-
-	n, err := r.r.Read(buf)
+	_, err = r.r.Seek(start, io.SeekStart)
 	if err != nil {
 		return "", err
 	}
-	if n != length {
-		return "", errors.New("Failed to re-read text\n")
+	buf, err := readBytes(r.r, length)
+	if err != nil {
+		return "", err
 	}
 
 	return string(buf), nil
 }
 
 func (r *base) ReadTo() (bool, error) {
-	fmt.Printf("reading past image segment... ")
-	b := []byte{0x00}
-	passed := 0
+	start, err := r.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	pos := start
 
+	// Scan large chunks, rather than reading a byte at a time; entropy-coded
+	// data can be many megabytes long.
+	pending := false
 	for {
-		n, err := r.r.Read(b)
-		if n != 1 || err != nil {
-			return false, errors.New("Failed to read 1 byte")
+		chunk, err := r.window(pos)
+		if err != nil {
+			return false, err
+		}
+		if len(chunk) == 0 {
+			return false, fmt.Errorf("%w: no marker after %d bytes", common.ErrTruncatedSegment, pos-start)
 		}
-		passed++
-		if b[0] == '\xff' {
-			// Check the next byte; if it is non-0x00, we are done.
-			n, err := r.r.Read(b)
-			if n != 1 || err != nil {
-				return false, errors.New("Failed to read 1 next byte")
+
+		i := 0
+		if pending {
+			// The previous chunk ended with 0xff
+			if chunk[0] != 0x00 {
+				return r.foundMarker(start, pos-1)
+			}
+			i = 1
+		}
+		pending = false
+
+		for {
+			k := bytes.IndexByte(chunk[i:], 0xff)
+			if k < 0 {
+				break
+			}
+			k += i
+			if k+1 == len(chunk) {
+				pending = true
+				break
 			}
-			if b[0] != '\x00' {
+			if chunk[k+1] != 0x00 {
 				// Found 0xffxx, where xx != 00
-				r.r.Seek(-2, io.SeekCurrent)
-				fmt.Printf("after %d bytes, found non-escaped 0xff\n", passed)
-				return true, nil
+				return r.foundMarker(start, pos+int64(k))
 			}
+			i = k + 2
 		}
+		pos += int64(len(chunk))
+	}
+}
+
+// window returns the scanned bytes from the provided absolute offset to the
+// end of the scan buffer, refilling the buffer if the offset is outside it.
+// Restart markers split entropy-coded data into many short runs, so each
+// call to ReadTo usually finds its bytes already in the buffer.  An empty
+// slice means the end of the stream.
+func (r *base) window(pos int64) ([]byte, error) {
+	if pos >= r.scanStart && pos < r.scanStart+int64(len(r.scan)) {
+		return r.scan[pos-r.scanStart:], nil
+	}
+
+	if r.scan == nil {
+		r.scan = make([]byte, scanChunkSize)
+	}
+	if _, err := r.r.Seek(pos, io.SeekStart); err != nil {
+		return nil, err
 	}
+	n, err := io.ReadFull(r.r, r.scan[:cap(r.scan)])
+	r.scan, r.scanStart = r.scan[:n], pos
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		r.scan = r.scan[:0]
+		return nil, err
+	}
+	return r.scan, nil
+}
+
+// foundMarker positions the reader on the 0xff at the provided absolute
+// offset
+func (r *base) foundMarker(start, offset int64) (bool, error) {
+	_, err := r.r.Seek(offset, io.SeekStart)
+	if err != nil {
+		return false, err
+	}
+	r.logger.Debug("Found non-escaped 0xff", "passed", offset-start)
+	return true, nil
+}
+
+func (r *base) ReadBytes(count int) ([]byte, error) {
+	return readBytes(r.r, count)
 }
 
 func (r *base) ReadUint8() (uint8, error) {
@@ -105,19 +191,27 @@ func (r *base) ReadUint8() (uint8, error) {
 
 func readBytes(r io.Reader, size int) ([]byte, error) {
 	t := make([]byte, size)
-	bytesRead, err := r.Read(t)
+	_, err := readFull(r, t)
 	if err != nil {
 		return nil, err
-	} else if bytesRead != size {
-		return nil, fmt.Errorf("Was only able to read %d of %d bytes", bytesRead, size)
 	}
 	return t, nil
 }
 
+// readFull fills b from r.  If the stream ends first, the returned error wraps
+// common.ErrTruncatedSegment.
+func readFull(r io.Reader, b []byte) (int, error) {
+	bytesRead, err := io.ReadFull(r, b)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return bytesRead, fmt.Errorf("%w: was only able to read %d of %d bytes", common.ErrTruncatedSegment, bytesRead, len(b))
+	}
+	return bytesRead, err
+}
+
 // Seek moves the internal byte pointer to the specified offset, relative to
 // the start of the underlying storage
 func (r *base) SeekTo(offset int64) error {
 	dest := r.offset + offset
-	r.r.Seek(dest, io.SeekStart)
-	return nil
+	_, err := r.r.Seek(dest, io.SeekStart)
+	return err
 }
@@ -3,6 +3,7 @@ package reader
 import (
 	"encoding/binary"
 	"io"
+	"log/slog"
 )
 
 // BigEndianReader is a wrapper around `bytes.Reader` with respect towards
@@ -12,28 +13,27 @@ type BigEndianReader struct {
 }
 
 // CreateBigEndianReader wraps an io.Reader with logic to read big-endian byte
-// content.  Operations in the reader are relative to the provided baseOffset.
-func CreateBigEndianReader(r io.ReadSeeker, baseOffset int64) Reader {
+// content.  Operations in the reader are relative to the provided baseOffset,
+// and debug output is written to logger.
+func CreateBigEndianReader(r io.ReadSeeker, baseOffset int64, logger *slog.Logger) Reader {
 	return &BigEndianReader{
-		base: base{r, baseOffset},
+		base: base{r: r, offset: baseOffset, logger: logger},
 	}
 }
 
+// GetByteOrder returns binary.BigEndian
+func (r *BigEndianReader) GetByteOrder() binary.ByteOrder {
+	return binary.BigEndian
+}
+
 // ReadUint8FromUint32 reads uint8s off the provided uint32, up to a maximum of
 // count
 func (r *BigEndianReader) ReadUint8FromUint32(count, data uint32) ([]uint32, error) {
 	result := make([]uint32, count)
-	result[0] = data & 0xff
-	if count > 0 {
-		result[1] = data & 0xff00 >> 8
-		if count > 1 {
-			result[2] = data & 0xff0000 >> 16
-			if count > 2 {
-				result[3] = data & 0xff000000 >> 24
-			}
-		}
+	for i := uint32(0); i < count && i < 4; i++ {
+		// The first byte in the stream is the most significant.
+		result[i] = data >> (24 - 8*i) & 0xff
 	}
-
 	return result, nil
 }
 
@@ -50,9 +50,9 @@ func (r *BigEndianReader) ReadUint16() (uint16, error) {
 // of count
 func (r *BigEndianReader) ReadUint16FromUint32(count, data uint32) ([]uint32, error) {
 	result := make([]uint32, count)
-	result[0] = data & 0xffff
-	if count > 0 {
-		result[1] = data & 0xffff0000 >> 16
+	for i := uint32(0); i < count && i < 2; i++ {
+		// The first short in the stream is the most significant.
+		result[i] = data >> (16 - 16*i) & 0xffff
 	}
 	return result, nil
 }
@@ -3,6 +3,7 @@ package reader
 import (
 	"encoding/binary"
 	"io"
+	"log/slog"
 )
 
 // LittleEndianReader is a wrapper around `bytes.Reader` with respect towards
@@ -13,28 +14,26 @@ type LittleEndianReader struct {
 
 // CreateLittleEndianReader wraps an io.Reader with logic to read little-endian
 // byte content.  Operations in the reader are relative to the provided
-// baseOffset.
-func CreateLittleEndianReader(r io.ReadSeeker, baseOffset int64) *LittleEndianReader {
+// baseOffset, and debug output is written to logger.
+func CreateLittleEndianReader(r io.ReadSeeker, baseOffset int64, logger *slog.Logger) *LittleEndianReader {
 	return &LittleEndianReader{
-		base: base{r, baseOffset},
+		base: base{r: r, offset: baseOffset, logger: logger},
 	}
 }
 
+// GetByteOrder returns binary.LittleEndian
+func (r *LittleEndianReader) GetByteOrder() binary.ByteOrder {
+	return binary.LittleEndian
+}
+
 // ReadUint8FromUint32 reads uint8s off the provided uint32, up to a maximum of
 // count
 func (r *LittleEndianReader) ReadUint8FromUint32(count, data uint32) ([]uint32, error) {
 	result := make([]uint32, count)
-	result[0] = data & 0xff000000 >> 24
-	if count > 1 {
-		result[1] = data & 0xff0000 >> 16
-		if count > 2 {
-			result[2] = data & 0xff00 >> 8
-			if count > 3 {
-				result[3] = data & 0xff
-			}
-		}
+	for i := uint32(0); i < count && i < 4; i++ {
+		// The first byte in the stream is the least significant.
+		result[i] = data >> (8 * i) & 0xff
 	}
-
 	return result, nil
 }
 
@@ -51,9 +50,9 @@ func (r *LittleEndianReader) ReadUint16() (uint16, error) {
 // of count
 func (r *LittleEndianReader) ReadUint16FromUint32(count, data uint32) ([]uint32, error) {
 	result := make([]uint32, count)
-	result[0] = data & 0xffff0000 >> 16
-	if count > 1 {
-		result[1] = data & 0xffff
+	for i := uint32(0); i < count && i < 2; i++ {
+		// The first short in the stream is the least significant.
+		result[i] = data >> (16 * i) & 0xffff
 	}
 	return result, nil
 }
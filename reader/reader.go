@@ -1,24 +1,39 @@
 package reader
 
-import "io"
+import (
+	"encoding/binary"
+	"io"
+)
 
 // Reader is a byte reader whose implementations is endian-aware
 type Reader interface {
 	// Discard fast-forwards over `count` bytes, discarding their contents
 	Discard(count int64) error
 
+	// GetByteOrder returns the byte order used to decode multi-byte values
+	GetByteOrder() binary.ByteOrder
+
 	// GetCurrentOffset returns the current offset relative to the starting offset
-	GetCurrentOffset() int64
+	GetCurrentOffset() (int64, error)
 
 	// GetReader returns the underlying ReadSeeker
 	GetReader() io.ReadSeeker
 
+	// GetSize returns the number of bytes between the starting offset and the
+	// end of the underlying storage
+	GetSize() (int64, error)
+
 	// ReadNullTerminatedString reads a series of bytes, until it encounters
 	// '\000', and returns a string.
 	ReadNullTerminatedString() (string, error)
 
+	// ReadTo scans forward to the next 0xff byte which is not followed by
+	// 0x00, and leaves the reader positioned on it.
 	ReadTo() (bool, error)
 
+	// ReadBytes reads count bytes
+	ReadBytes(count int) ([]byte, error)
+
 	// ReadUint8 reads an unsigned 8-bit value
 	ReadUint8() (uint8, error)
 
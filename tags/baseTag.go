@@ -2,7 +2,9 @@ package tags
 
 import "github.com/object88/go-image-metadata/common"
 
-// BaseTag is the common struct that other type-specific tags are composed with
+// BaseTag is the common struct that other type-specific tags are composed
+// with.  Its accessors return empty values; the type-specific tags override
+// the accessors which apply to them.
 type BaseTag struct {
 	name    string
 	tagID   TagID
@@ -23,3 +25,48 @@ func (b *BaseTag) GetName() string {
 func (b *BaseTag) GetType() common.DataFormat {
 	return b.tagType
 }
+
+// GetCount returns 0
+func (b *BaseTag) GetCount() int {
+	return 0
+}
+
+// Uint32s returns nil
+func (b *BaseTag) Uint32s() []uint32 {
+	return nil
+}
+
+// Int32s returns nil
+func (b *BaseTag) Int32s() []int32 {
+	return nil
+}
+
+// Rationals returns nil
+func (b *BaseTag) Rationals() []Rational {
+	return nil
+}
+
+// Float64s returns nil
+func (b *BaseTag) Float64s() []float64 {
+	return nil
+}
+
+// Strings returns nil
+func (b *BaseTag) Strings() []string {
+	return nil
+}
+
+// Bytes returns nil
+func (b *BaseTag) Bytes() []byte {
+	return nil
+}
+
+// AsInt is not convertible
+func (b *BaseTag) AsInt() (int64, bool) {
+	return 0, false
+}
+
+// AsFloat is not convertible
+func (b *BaseTag) AsFloat() (float64, bool) {
+	return 0, false
+}
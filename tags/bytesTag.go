@@ -0,0 +1,64 @@
+package tags
+
+import (
+	"fmt"
+)
+
+// BytesTag holds the raw payload of a tag with the undefined data format
+type BytesTag struct {
+	BaseTag
+	value []byte
+}
+
+func (m *BytesTag) String() string {
+	if len(m.value) <= 16 {
+		return fmt.Sprintf("%s (%s) [% x]", m.GetName(), m.GetType(), m.value)
+	}
+	return fmt.Sprintf("%s (%s) [% x ...] (%d bytes)", m.GetName(), m.GetType(), m.value[:16], len(m.value))
+}
+
+// GetCount returns the number of bytes
+func (m *BytesTag) GetCount() int {
+	return len(m.value)
+}
+
+// Bytes returns the payload
+func (m *BytesTag) Bytes() []byte {
+	return m.value
+}
+
+func readUndefined(reader TagReader, name string, raw *RawTagData) (Tag, bool, error) {
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return &BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}, true, nil
+}
+
+// readRawBytes reads raw.Count bytes, either from the data field itself or
+// from the offset it holds.
+func readRawBytes(reader TagReader, raw *RawTagData) ([]byte, error) {
+	r := reader.GetReader()
+	if raw.Count <= 4 {
+		v, err := r.ReadUint8FromUint32(raw.Count, raw.Data)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, len(v))
+		for k, n := range v {
+			b[k] = byte(n)
+		}
+		return b, nil
+	}
+
+	var b []byte
+	err := readAt(r, raw.Data, uint64(raw.Count), func() error {
+		var err error
+		b, err = r.ReadBytes(int(raw.Count))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
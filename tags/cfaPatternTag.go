@@ -0,0 +1,90 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+var cfaColors = [...]string{"R", "G", "B", "C", "M", "Y", "W"}
+
+// CFAPatternTag holds the color filter array geometry of the image sensor.
+// Colors are numbered as in the Exif standard: 0 = red, 1 = green, 2 = blue,
+// 3 = cyan, 4 = magenta, 5 = yellow, 6 = white.
+type CFAPatternTag struct {
+	BytesTag
+	pattern [][]uint8
+}
+
+func (m *CFAPatternTag) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(m.GetName())
+	buffer.WriteString(" [")
+	for k, row := range m.pattern {
+		buffer.WriteRune('[')
+		for k1, c := range row {
+			if int(c) < len(cfaColors) {
+				buffer.WriteString(cfaColors[c])
+			} else {
+				buffer.WriteString(strconv.Itoa(int(c)))
+			}
+			if k1 != len(row)-1 {
+				buffer.WriteRune(' ')
+			}
+		}
+		buffer.WriteRune(']')
+		if k != len(m.pattern)-1 {
+			buffer.WriteRune(' ')
+		}
+	}
+	buffer.WriteRune(']')
+	return buffer.String()
+}
+
+// Pattern returns the grid of colors, indexed by row and then column
+func (m *CFAPatternTag) Pattern() [][]uint8 {
+	return m.pattern
+}
+
+func readCFAPattern(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	if raw.Format != common.Undefined {
+		return defaultInitializer(reader, ifd, name, raw)
+	}
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	tag := &CFAPatternTag{BytesTag: BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}}
+	if len(b) < 4 {
+		return tag, true, nil
+	}
+
+	// The repeat dimensions are meant to be in the byte order of the TIFF
+	// header, but some cameras always write them big-endian.
+	columns, rows := decodeCFADimensions(b, reader.GetReader().GetByteOrder())
+	if columns*rows != len(b)-4 {
+		columns, rows = decodeCFADimensions(b, otherByteOrder(reader.GetReader().GetByteOrder()))
+	}
+	if columns*rows != len(b)-4 {
+		return tag, true, nil
+	}
+
+	tag.pattern = make([][]uint8, rows)
+	for row := 0; row < rows; row++ {
+		tag.pattern[row] = b[4+row*columns : 4+(row+1)*columns]
+	}
+	return tag, true, nil
+}
+
+func decodeCFADimensions(b []byte, order binary.ByteOrder) (int, int) {
+	return int(order.Uint16(b[0:])), int(order.Uint16(b[2:]))
+}
+
+func otherByteOrder(order binary.ByteOrder) binary.ByteOrder {
+	if order == binary.BigEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
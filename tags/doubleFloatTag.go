@@ -2,6 +2,7 @@ package tags
 
 import (
 	"bytes"
+	"math"
 	"strconv"
 
 	"github.com/object88/go-image-metadata/common"
@@ -30,15 +31,40 @@ func (m *DoubleFloatTag) String() string {
 	return buffer.String()
 }
 
+// GetCount returns the number of floats
+func (m *DoubleFloatTag) GetCount() int {
+	return len(m.value)
+}
+
+// Float64s returns the floats
+func (m *DoubleFloatTag) Float64s() []float64 {
+	return m.value
+}
+
+// AsFloat returns the first float
+func (m *DoubleFloatTag) AsFloat() (float64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return float64(m.value[0]), true
+}
+
 func readDoubleFloat(reader TagReader, name string, raw *RawTagData) (Tag, bool, error) {
 	r := reader.GetReader()
-	cur := r.GetCurrentOffset()
-	r.SeekTo(int64(raw.Data))
-	v := make([]float64, raw.Count)
-	for i := uint32(0); i < raw.Count; i++ {
-		n, _ := r.ReadUint64()
-		v[i] = float64(n)
+	var v []float64
+	err := readAt(r, raw.Data, 8*uint64(raw.Count), func() error {
+		v = make([]float64, raw.Count)
+		for i := uint32(0); i < raw.Count; i++ {
+			n, err := r.ReadUint64()
+			if err != nil {
+				return err
+			}
+			v[i] = math.Float64frombits(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
-	r.SeekTo(cur)
 	return &DoubleFloatTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
 }
@@ -0,0 +1,147 @@
+package tags
+
+// IfdID identifies the role of an IFD within an image file
+type IfdID int
+
+const (
+	// Ifd0 is the first IFD in the main chain, describing the primary image
+	Ifd0 IfdID = iota
+
+	// Ifd1 is the second IFD in the main chain, usually describing a thumbnail
+	Ifd1
+
+	// ImageIfd is any subsequent IFD in the main chain, as found in multi-page
+	// TIFFs; Ifd.Index holds its position in the chain
+	ImageIfd
+
+	// ExifIfd is pointed at by the "Exif IFD" tag
+	ExifIfd
+
+	// GpsIfd is pointed at by the "GPS IFD" tag
+	GpsIfd
+
+	// InteropIfd is pointed at by the "Interoperability" tag of the Exif IFD
+	InteropIfd
+
+	// MakerNoteIfd is a vendor-specific IFD stored in the MakerNote tag
+	MakerNoteIfd
+
+	// SubIfd is one of the IFDs pointed at by the "SubIFDs" tag; Ifd.Index
+	// holds its position in the tag's array
+	SubIfd
+
+	// MPIndexIfd is the first IFD of a Multi-Picture Format segment, listing
+	// the images in the file
+	MPIndexIfd
+
+	// MPAttributeIfd follows the MP Index IFD, describing an individual image
+	// of a Multi-Picture Format file
+	MPAttributeIfd
+)
+
+var ifdIDs = [...]string{
+	"IFD0",
+	"IFD1",
+	"IFD",
+	"Exif",
+	"GPS",
+	"Interop",
+	"MakerNote",
+	"SubIFD",
+	"MPIndex",
+	"MPAttribute",
+}
+
+func (id IfdID) String() string {
+	return ifdIDs[id]
+}
+
+// Ifd is a single image file directory, holding its tags in the order they
+// were read, and any IFDs its tags pointed at.
+type Ifd struct {
+	// ID describes the role of the IFD
+	ID IfdID
+
+	// Index is the position of the IFD in its chain, or in the array of
+	// offsets which pointed at it
+	Index int
+
+	// Offset is the location of the IFD, relative to the TIFF header
+	Offset uint32
+
+	// Tags holds the tags in file order
+	Tags []Tag
+
+	// Children holds the IFDs pointed at by tags in this IFD, in file order
+	Children []*Ifd
+}
+
+// Get returns the first tag in this IFD with the provided ID
+func (ifd *Ifd) Get(id TagID) (Tag, bool) {
+	for _, t := range ifd.Tags {
+		if t.GetID() == id {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (ifd *Ifd) walk(fn func(ifd *Ifd, tag Tag) bool) bool {
+	for _, t := range ifd.Tags {
+		if !fn(ifd, t) {
+			return false
+		}
+	}
+	for _, child := range ifd.Children {
+		if !child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Tree is the hierarchy of IFDs read from an image file.  Ifds holds the
+// main chain (IFD0, IFD1, ...), followed by any other top-level chains, such
+// as the MPF index; all other IFDs are found as children.
+type Tree struct {
+	Ifds []*Ifd
+}
+
+// Find returns all IFDs with the provided ID, in file order
+func (t *Tree) Find(id IfdID) []*Ifd {
+	found := []*Ifd{}
+	t.eachIfd(t.Ifds, func(ifd *Ifd) {
+		if ifd.ID == id {
+			found = append(found, ifd)
+		}
+	})
+	return found
+}
+
+// Get returns the first tag with the provided ID, in the first IFD with the
+// provided IFD ID which contains it
+func (t *Tree) Get(ifd IfdID, id TagID) (Tag, bool) {
+	for _, i := range t.Find(ifd) {
+		if tag, ok := i.Get(id); ok {
+			return tag, true
+		}
+	}
+	return nil, false
+}
+
+// Walk calls fn with each tag in the tree.  Each IFD's tags are visited in
+// file order, followed by its children.  Walking stops if fn returns false.
+func (t *Tree) Walk(fn func(ifd *Ifd, tag Tag) bool) {
+	for _, ifd := range t.Ifds {
+		if !ifd.walk(fn) {
+			return
+		}
+	}
+}
+
+func (t *Tree) eachIfd(ifds []*Ifd, fn func(ifd *Ifd)) {
+	for _, ifd := range ifds {
+		fn(ifd)
+		t.eachIfd(ifd.Children, fn)
+	}
+}
@@ -0,0 +1,122 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// makerNoteFormat describes where a vendor's MakerNote keeps its IFD, and
+// what its offsets are relative to
+type makerNoteFormat struct {
+	prefix string
+
+	// ifd is the offset of the IFD within the note, or of the embedded TIFF
+	// header if header is true
+	ifd uint32
+
+	// header is true if the note embeds a TIFF header, which gives the byte
+	// order and the IFD's offset, and is the origin of all offsets
+	header bool
+
+	// relative is true if offsets are relative to the start of the note,
+	// rather than to the TIFF header of the Exif data
+	relative bool
+
+	// order is the note's fixed byte order, or nil if it shares the Exif
+	// data's byte order
+	order binary.ByteOrder
+}
+
+// makerNoteFormats lists the notes which start with an identifying prefix.
+// Notes without one, such as Canon's, start directly with an IFD.
+var makerNoteFormats = []makerNoteFormat{
+	{prefix: "Nikon\x00\x02", ifd: 10, header: true},
+	{prefix: "Nikon\x00\x01", ifd: 8},
+	{prefix: "OLYMPUS\x00II", ifd: 12, relative: true, order: binary.LittleEndian},
+	{prefix: "OLYMPUS\x00MM", ifd: 12, relative: true, order: binary.BigEndian},
+	{prefix: "OLYMP\x00", ifd: 8},
+	{prefix: "FUJIFILM", ifd: 12, relative: true, order: binary.LittleEndian},
+	{prefix: "SONY DSC \x00\x00\x00", ifd: 12},
+	{prefix: "SONY CAM \x00\x00\x00", ifd: 12},
+	{prefix: "Panasonic\x00\x00\x00", ifd: 12},
+	{prefix: "Apple iOS\x00", ifd: 14, relative: true, order: binary.BigEndian},
+}
+
+// readMakerNote keeps the MakerNote's bytes, and reads the vendor IFD it
+// holds into a MakerNoteIfd child.  If the IFD cannot be read, the tag is
+// still returned alongside the error.
+func readMakerNote(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	if raw.Format != common.Undefined || raw.Count <= 4 {
+		return defaultInitializer(reader, ifd, name, raw)
+	}
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	tag := &BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}
+
+	r := reader.GetReader()
+	cur, err := r.GetCurrentOffset()
+	if err != nil {
+		return tag, true, err
+	}
+	chain, err := followMakerNote(reader, raw.Data, b)
+	ifd.Children = append(ifd.Children, chain...)
+	if seekErr := r.SeekTo(cur); err == nil {
+		err = seekErr
+	}
+	if err != nil {
+		return tag, true, fmt.Errorf("Failed to read MakerNote IFD: %w", err)
+	}
+	return tag, true, nil
+}
+
+// followMakerNote reads the IFD of a note at the provided offset
+func followMakerNote(reader TagReader, offset uint32, b []byte) ([]*Ifd, error) {
+	for _, f := range makerNoteFormats {
+		if !bytes.HasPrefix(b, []byte(f.prefix)) {
+			continue
+		}
+		switch {
+		case f.header:
+			if uint32(len(b)) < f.ifd+8 {
+				return nil, fmt.Errorf("%w: MakerNote TIFF header", common.ErrTruncatedSegment)
+			}
+			var order binary.ByteOrder
+			switch string(b[f.ifd : f.ifd+2]) {
+			case "II":
+				order = binary.LittleEndian
+			case "MM":
+				order = binary.BigEndian
+			default:
+				return nil, fmt.Errorf("MakerNote has unknown byte order % x", b[f.ifd:f.ifd+2])
+			}
+			return reader.ReadRebasedIfd(offset+f.ifd, order, order.Uint32(b[f.ifd+4:]), MakerNoteIfd, nil)
+		case f.relative:
+			return reader.ReadRebasedIfd(offset, f.order, f.ifd, MakerNoteIfd, nil)
+		}
+		return reader.ReadIfd(offset+f.ifd, MakerNoteIfd, nil)
+	}
+
+	// Without a prefix, only read the note if it looks like an IFD
+	order := reader.GetReader().GetByteOrder()
+	if len(b) < 2+12 {
+		return nil, nil
+	}
+	count := int(order.Uint16(b))
+	format := common.DataFormat(order.Uint16(b[4:]))
+	if _, ok := common.DataFormatSizes[format]; count == 0 || 2+12*count > len(b) || !ok {
+		return nil, nil
+	}
+	return reader.ReadIfd(offset, MakerNoteIfd, nil)
+}
+
+// ReadUnknownTag decodes a tag which is not in any tag map by its data
+// format, naming it by its ID.  Vendor IFDs, such as MakerNotes, hold tags
+// which are mostly undocumented.
+func ReadUnknownTag(reader TagReader, ifd *Ifd, raw *RawTagData) (Tag, bool, error) {
+	return defaultInitializer(reader, ifd, fmt.Sprintf("0x%04x", uint16(raw.Tag)), raw)
+}
@@ -0,0 +1,141 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+const mpEntrySize = 16
+
+// MPEntryID is the ID of the MPEntry tag in MPFTagMap, found in the MP Index
+// IFD
+const MPEntryID TagID = 0xb002
+
+// MPType is the type code of an image in a Multi-Picture Format file
+type MPType uint32
+
+const (
+	// BaselinePrimary is the primary image of a baseline MP file
+	BaselinePrimary MPType = 0x030000
+
+	// LargeThumbnailVGA is a large thumbnail, up to 640x480
+	LargeThumbnailVGA MPType = 0x010001
+
+	// LargeThumbnailFullHD is a large thumbnail, up to 1920x1080
+	LargeThumbnailFullHD MPType = 0x010002
+
+	// MultiFramePanorama is one image of a panorama
+	MultiFramePanorama MPType = 0x020001
+
+	// MultiFrameDisparity is one image of a stereo or multi-view set
+	MultiFrameDisparity MPType = 0x020002
+
+	// MultiFrameMultiAngle is one image of a multi-angle set
+	MultiFrameMultiAngle MPType = 0x020003
+
+	// UndefinedMPType is used by images outside the MPF types, such as depth and
+	// gain maps
+	UndefinedMPType MPType = 0x000000
+)
+
+var mpTypes = map[MPType]string{
+	BaselinePrimary:      "baseline MP primary image",
+	LargeThumbnailVGA:    "large thumbnail (VGA)",
+	LargeThumbnailFullHD: "large thumbnail (full HD)",
+	MultiFramePanorama:   "multi-frame panorama",
+	MultiFrameDisparity:  "multi-frame disparity",
+	MultiFrameMultiAngle: "multi-frame multi-angle",
+	UndefinedMPType:      "undefined",
+}
+
+func (t MPType) String() string {
+	if s, ok := mpTypes[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("0x%06x", uint32(t))
+}
+
+// MPEntry describes one image in the MP Index IFD
+type MPEntry struct {
+	// DependentParent is true if the image has dependent images
+	DependentParent bool
+
+	// DependentChild is true if the image depends on another image
+	DependentChild bool
+
+	// Representative is true if the image is the representative image
+	Representative bool
+
+	// Format is the image data format; 0 is JPEG
+	Format uint8
+
+	Type MPType
+
+	// Size is the length of the image, in bytes
+	Size uint32
+
+	// Offset is the position of the image, relative to the MPF header.  It is
+	// 0 for the first image, which starts at the beginning of the file.
+	Offset uint32
+
+	// Dependent1 and Dependent2 are the 1-based entry numbers of dependent
+	// images, or 0
+	Dependent1 uint16
+	Dependent2 uint16
+}
+
+// MPEntryTag holds the list of images in a Multi-Picture Format file
+type MPEntryTag struct {
+	BytesTag
+	entries []MPEntry
+}
+
+func (m *MPEntryTag) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(m.GetName())
+	buffer.WriteString(" [")
+	for k, e := range m.entries {
+		fmt.Fprintf(&buffer, "{%s %d bytes at 0x%08x}", e.Type, e.Size, e.Offset)
+		if k != len(m.entries)-1 {
+			buffer.WriteRune(' ')
+		}
+	}
+	buffer.WriteRune(']')
+	return buffer.String()
+}
+
+// Entries returns the images, in index order
+func (m *MPEntryTag) Entries() []MPEntry {
+	return m.entries
+}
+
+func readMPEntry(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	if raw.Format != common.Undefined {
+		return defaultInitializer(reader, ifd, name, raw)
+	}
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+
+	order := reader.GetReader().GetByteOrder()
+	entries := make([]MPEntry, len(b)/mpEntrySize)
+	for k := range entries {
+		e := b[k*mpEntrySize:]
+		attribute := order.Uint32(e[0:])
+		entries[k] = MPEntry{
+			DependentParent: attribute&0x80000000 != 0,
+			DependentChild:  attribute&0x40000000 != 0,
+			Representative:  attribute&0x20000000 != 0,
+			Format:          uint8(attribute>>24) & 0x07,
+			Type:            MPType(attribute & 0x00ffffff),
+			Size:            order.Uint32(e[4:]),
+			Offset:          order.Uint32(e[8:]),
+			Dependent1:      order.Uint16(e[12:]),
+			Dependent2:      order.Uint16(e[14:]),
+		}
+	}
+	return &MPEntryTag{BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}, entries}, true, nil
+}
@@ -0,0 +1,35 @@
+package tags
+
+import (
+	"fmt"
+
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/reader"
+)
+
+// readAt moves the reader to the provided offset, calls fn to read size bytes,
+// and then returns the reader to the position it held beforehand.  If the
+// bytes would extend past the end of the byte stream, fn is not called.
+func readAt(r reader.Reader, offset uint32, size uint64, fn func() error) error {
+	end, err := r.GetSize()
+	if err != nil {
+		return err
+	}
+	if uint64(offset)+size > uint64(end) {
+		return fmt.Errorf("%w: %d bytes at 0x%08x extend past the end at 0x%08x", common.ErrTruncatedSegment, size, offset, end)
+	}
+
+	cur, err := r.GetCurrentOffset()
+	if err != nil {
+		return err
+	}
+	err = r.SeekTo(int64(offset))
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if seekErr := r.SeekTo(cur); err == nil {
+		err = seekErr
+	}
+	return err
+}
@@ -3,8 +3,6 @@ package tags
 import (
 	"bytes"
 	"strconv"
-
-	"github.com/object88/go-image-metadata/common"
 )
 
 // SignedIntegerTag holds an array of integers.  All values are stored as
@@ -23,35 +21,55 @@ func (m *SignedIntegerTag) String() string {
 	for k, v := range m.value {
 		buffer.WriteString(strconv.Itoa(int(v)))
 		if k != len(m.value)-1 {
-			buffer.WriteString("\", \"")
+			buffer.WriteString(", ")
 		}
 	}
 	buffer.WriteString("]")
 	return buffer.String()
 }
 
+// GetCount returns the number of integers
+func (m *SignedIntegerTag) GetCount() int {
+	return len(m.value)
+}
+
+// Int32s returns the integers
+func (m *SignedIntegerTag) Int32s() []int32 {
+	return m.value
+}
+
+// AsInt returns the first integer
+func (m *SignedIntegerTag) AsInt() (int64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return int64(m.value[0]), true
+}
+
+// AsFloat returns the first integer as a float
+func (m *SignedIntegerTag) AsFloat() (float64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return float64(m.value[0]), true
+}
+
 func readSignedInteger(reader TagReader, name string, dataSize uint32, raw *RawTagData) (Tag, bool, error) {
-	r := reader.GetReader()
-	v := make([]int32, raw.Count)
-	if dataSize*raw.Count > 4 {
-		cur := r.GetCurrentOffset()
-		r.SeekTo(int64(raw.Data))
-		// Read off the string of numbers...
-		r.SeekTo(cur)
-	} else {
-		if raw.Format == common.Sbyte {
-			for i := uint32(0); i < raw.Count; i++ {
-				n, _ := r.ReadUint8()
-				v[i] = int32(n)
-			}
-		} else if raw.Format == common.Sshort {
-			for i := uint32(0); i < raw.Count; i++ {
-				n, _ := r.ReadUint16()
-				v[i] = int32(n)
-			}
-		} else if raw.Format == common.Slong {
-			n, _ := r.ReadUint32()
-			v[0] = int32(n)
+	u, err := readIntegers(reader.GetReader(), dataSize, raw)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Sign-extend the values according to their size
+	v := make([]int32, len(u))
+	for k, n := range u {
+		switch dataSize {
+		case 1:
+			v[k] = int32(int8(n))
+		case 2:
+			v[k] = int32(int16(n))
+		default:
+			v[k] = int32(n)
 		}
 	}
 	return &SignedIntegerTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
@@ -37,16 +37,56 @@ func (m *SignedRationalTag) String() string {
 	return buffer.String()
 }
 
+// GetCount returns the number of rationals
+func (m *SignedRationalTag) GetCount() int {
+	return len(m.value)
+}
+
+// Rationals returns the rationals
+func (m *SignedRationalTag) Rationals() []Rational {
+	v := make([]Rational, len(m.value))
+	for k, r := range m.value {
+		v[k] = Rational{Numerator: int64(r.Numerator), Denominator: int64(r.Denominator)}
+	}
+	return v
+}
+
+// AsInt returns the first rational, if it is a whole number
+func (m *SignedRationalTag) AsInt() (int64, bool) {
+	if len(m.value) == 0 || m.value[0].Denominator == 0 || m.value[0].Numerator%m.value[0].Denominator != 0 {
+		return 0, false
+	}
+	return int64(m.value[0].Numerator / m.value[0].Denominator), true
+}
+
+// AsFloat returns the first rational as a float
+func (m *SignedRationalTag) AsFloat() (float64, bool) {
+	if len(m.value) == 0 || m.value[0].Denominator == 0 {
+		return 0, false
+	}
+	return float64(m.value[0].Numerator) / float64(m.value[0].Denominator), true
+}
+
 func readSignedRational(reader TagReader, name string, raw *RawTagData) (Tag, bool, error) {
 	r := reader.GetReader()
-	cur := r.GetCurrentOffset()
-	r.SeekTo(int64(raw.Data))
-	v := make([]SignedRational, raw.Count)
-	for i := uint32(0); i < raw.Count; i++ {
-		n, _ := r.ReadUint32()
-		d, _ := r.ReadUint32()
-		v[i] = SignedRational{Numerator: int32(n), Denominator: int32(d)}
+	var v []SignedRational
+	err := readAt(r, raw.Data, 8*uint64(raw.Count), func() error {
+		v = make([]SignedRational, raw.Count)
+		for i := uint32(0); i < raw.Count; i++ {
+			n, err := r.ReadUint32()
+			if err != nil {
+				return err
+			}
+			d, err := r.ReadUint32()
+			if err != nil {
+				return err
+			}
+			v[i] = SignedRational{Numerator: int32(n), Denominator: int32(d)}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
-	r.SeekTo(cur)
 	return &SignedRationalTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
 }
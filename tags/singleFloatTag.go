@@ -2,6 +2,7 @@ package tags
 
 import (
 	"bytes"
+	"math"
 	"strconv"
 
 	"github.com/object88/go-image-metadata/common"
@@ -30,20 +31,49 @@ func (m *SingleFloatTag) String() string {
 	return buffer.String()
 }
 
+// GetCount returns the number of floats
+func (m *SingleFloatTag) GetCount() int {
+	return len(m.value)
+}
+
+// Float64s returns the floats
+func (m *SingleFloatTag) Float64s() []float64 {
+	v := make([]float64, len(m.value))
+	for k, f := range m.value {
+		v[k] = float64(f)
+	}
+	return v
+}
+
+// AsFloat returns the first float
+func (m *SingleFloatTag) AsFloat() (float64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return float64(m.value[0]), true
+}
+
 func readSingleFloat(reader TagReader, name string, raw *RawTagData) (Tag, bool, error) {
 	r := reader.GetReader()
-	v := make([]float32, raw.Count)
+	var v []float32
 	if raw.Count == 1 {
-		n, _ := r.ReadUint32()
-		v[0] = float32(n)
+		// A single float fits in the data field
+		v = []float32{math.Float32frombits(raw.Data)}
 	} else {
-		cur := r.GetCurrentOffset()
-		r.SeekTo(int64(raw.Data))
-		for i := uint32(0); i < raw.Count; i++ {
-			n, _ := r.ReadUint32()
-			v[i] = float32(n)
+		err := readAt(r, raw.Data, 4*uint64(raw.Count), func() error {
+			v = make([]float32, raw.Count)
+			for i := uint32(0); i < raw.Count; i++ {
+				n, err := r.ReadUint32()
+				if err != nil {
+					return err
+				}
+				v[i] = math.Float32frombits(n)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
 		}
-		r.SeekTo(cur)
 	}
 	return &SingleFloatTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
 }
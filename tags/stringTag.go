@@ -2,12 +2,15 @@ package tags
 
 import (
 	"bytes"
+	"strings"
+	"unicode/utf8"
 )
 
 // StringTag holds an array of strings
 type StringTag struct {
 	BaseTag
 	value []string
+	utf8  bool
 }
 
 func (m *StringTag) String() string {
@@ -24,6 +27,23 @@ func (m *StringTag) String() string {
 	return buffer.String()
 }
 
+// GetCount returns the number of strings
+func (m *StringTag) GetCount() int {
+	return len(m.value)
+}
+
+// Strings returns the strings
+func (m *StringTag) Strings() []string {
+	return m.value
+}
+
+// IsUTF8 reports whether the strings contain non-ASCII characters which form
+// valid UTF-8.  The TIFF standard only permits 7-bit ASCII, but many cameras
+// and editors write UTF-8 regardless.
+func (m *StringTag) IsUTF8() bool {
+	return m.utf8
+}
+
 func readASCIIString(reader TagReader, name string, raw *RawTagData) (Tag, bool, error) {
 	// From the TIFF-v6 spec:
 	// Any ASCII field can contain multiple strings, each terminated with a NUL. A
@@ -31,10 +51,29 @@ func readASCIIString(reader TagReader, name string, raw *RawTagData) (Tag, bool,
 	// the number of bytes in all the strings in that field plus their terminating NUL
 	// bytes. Only one NUL is allowed between strings, so that the strings following the
 	// first string will often begin on an odd byte.
-	// ... so this is not sufficient.
-	cur := reader.GetReader().GetCurrentOffset()
-	reader.GetReader().SeekTo(int64(raw.Data))
-	s, _ := reader.GetReader().ReadNullTerminatedString()
-	reader.GetReader().SeekTo(cur)
-	return &StringTag{BaseTag{name, raw.Tag, raw.Format}, []string{s}}, true, nil
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return &StringTag{BaseTag{name, raw.Tag, raw.Format}, splitASCII(b), isUTF8(b)}, true, nil
+}
+
+// splitASCII splits NUL-terminated strings.  Trailing padding is dropped, and
+// a final string without a terminator is tolerated.
+func splitASCII(b []byte) []string {
+	s := strings.TrimRight(string(b), "\x00")
+	if len(s) == 0 {
+		return []string{}
+	}
+	return strings.Split(s, "\x00")
+}
+
+// isUTF8 reports whether b holds non-ASCII bytes which are valid UTF-8
+func isUTF8(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return utf8.Valid(b)
+		}
+	}
+	return false
 }
@@ -1,9 +1,6 @@
 package tags
 
 import (
-	"errors"
-	"fmt"
-
 	"github.com/object88/go-image-metadata/common"
 )
 
@@ -25,6 +22,11 @@ var GpsTagMap map[uint16]TagBuilder
 // REf: http://www.awaresystems.be/imaging/tiff/tifftags/privateifd/interoperability.html
 var InteropTagMap map[uint16]TagBuilder
 
+// MPFTagMap contains the tags of the Multi-Picture Format MP Index and MP
+// Attribute IFDs.
+// Ref: CIPA DC-007
+var MPFTagMap map[uint16]TagBuilder
+
 func init() {
 	TagMap = map[uint16]TagBuilder{
 		0x00fe: TagBuilder{name: "NewSubfileType"},
@@ -83,7 +85,27 @@ func init() {
 		0x0146: TagBuilder{name: "BadFaxLines"},
 		0x0147: TagBuilder{name: "CleanFaxData"},
 		0x0148: TagBuilder{name: "ConsecutiveBadFaxLines"},
-		0x014a: TagBuilder{name: "SubIFDs"},
+		0x014a: TagBuilder{
+			name: "SubIFDs",
+			initializer: func(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+				dataSize := common.DataFormatSizes[raw.Format]
+				offsets, ok, err := readUnsignedInteger(reader, name, dataSize, raw)
+				if err != nil || !ok {
+					return nil, ok, err
+				}
+				for k, offset := range offsets.Uint32s() {
+					chain, err := followIfd(reader, SubIfd, offset, []*map[uint16]TagBuilder{&TagMap})
+					for _, subIfd := range chain {
+						subIfd.Index = k
+					}
+					ifd.Children = append(ifd.Children, chain...)
+					if err != nil {
+						return nil, false, err
+					}
+				}
+				return offsets, true, nil
+			},
+		},
 		0x014c: TagBuilder{name: "InkSet"},
 		0x014d: TagBuilder{name: "InkNames"},
 		0x014e: TagBuilder{name: "NumberOfInks"},
@@ -144,12 +166,8 @@ func init() {
 		0x8649: TagBuilder{name: "Photoshop"},
 		0x8769: TagBuilder{
 			name: "Exif IFD",
-			initializer: func(reader TagReader, foundTags *map[uint16]Tag, name string, raw *RawTagData) (Tag, bool, error) {
-				r := reader.GetReader()
-				cur := r.GetCurrentOffset()
-				reader.ReadIfd(raw.Data, []*map[uint16]TagBuilder{&ExifTagMap, &TagMap}, foundTags)
-				r.SeekTo(cur)
-				return nil, true, nil
+			initializer: func(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+				return readSubIfd(reader, ifd, ExifIfd, raw.Data, []*map[uint16]TagBuilder{&ExifTagMap, &TagMap})
 			},
 		},
 		0x8773: TagBuilder{name: "ICC Profile"},
@@ -158,20 +176,15 @@ func init() {
 		0x87B1: TagBuilder{name: "GeoAsciiParamsTag"},
 		0x8825: TagBuilder{
 			name: "GPS IFD",
-			initializer: func(reader TagReader, foundTags *map[uint16]Tag, name string, raw *RawTagData) (Tag, bool, error) {
-				fmt.Printf("Found GPS IFD...\n")
-				r := reader.GetReader()
-				cur := r.GetCurrentOffset()
-				reader.ReadIfd(raw.Data, []*map[uint16]TagBuilder{&GpsTagMap}, foundTags)
-				r.SeekTo(cur)
-				return nil, true, nil
+			initializer: func(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+				return readSubIfd(reader, ifd, GpsIfd, raw.Data, []*map[uint16]TagBuilder{&GpsTagMap})
 			},
 		},
 		0x885C: TagBuilder{name: "HylaFAX FaxRecvParams"},
 		0x885D: TagBuilder{name: "HylaFAX FaxSubAddress"},
 		0x885E: TagBuilder{name: "HylaFAX FaxRecvTime"},
 		0x935C: TagBuilder{name: "ImageSourceData"},
-		0xa005: TagBuilder{name: "Interoperability"},
+		0xa005: TagBuilder{name: "Interoperability", initializer: readInteropIfd},
 		0xa480: TagBuilder{name: "GDAL_METADATA"},
 		0xa481: TagBuilder{name: "GDAL_NODATA"},
 		0xc427: TagBuilder{name: "Oce Scanjob Description"},
@@ -226,7 +239,7 @@ func init() {
 		0x8824: TagBuilder{name: "SpectralSensitivity"},
 		0x8827: TagBuilder{name: "ISOSpeedRatings"},
 		0x8828: TagBuilder{name: "OECF"},
-		0x9000: TagBuilder{name: "ExifVersion"},
+		0x9000: TagBuilder{name: "ExifVersion", initializer: readVersion},
 		0x9003: TagBuilder{name: "DateTimeOriginal"},
 		0x9004: TagBuilder{name: "DateTimeDigitized"},
 		0x9101: TagBuilder{name: "ComponentsConfiguration"},
@@ -242,16 +255,17 @@ func init() {
 		0x9209: TagBuilder{name: "Flash"},
 		0x920a: TagBuilder{name: "FocalLength"},
 		0x9214: TagBuilder{name: "SubjectArea"},
-		0x927c: TagBuilder{name: "MakerNote"},
-		0x9286: TagBuilder{name: "UserComment"},
+		0x927c: TagBuilder{name: "MakerNote", initializer: readMakerNote},
+		0x9286: TagBuilder{name: "UserComment", initializer: readUserComment},
 		0x9290: TagBuilder{name: "SubsecTime"},
 		0x9291: TagBuilder{name: "SubsecTimeOriginal"},
 		0x9292: TagBuilder{name: "SubsecTimeDigitized"},
-		0xa000: TagBuilder{name: "FlashpixVersion"},
+		0xa000: TagBuilder{name: "FlashpixVersion", initializer: readVersion},
 		0xa001: TagBuilder{name: "ColorSpace"},
 		0xa002: TagBuilder{name: "PixelXDimension"},
 		0xa003: TagBuilder{name: "PixelYDimension"},
 		0xa004: TagBuilder{name: "RelatedSoundFile"},
+		0xa005: TagBuilder{name: "Interoperability", initializer: readInteropIfd},
 		0xa20b: TagBuilder{name: "FlashEnergy"},
 		0xa20c: TagBuilder{name: "SpatialFrequencyResponse"},
 		0xa20e: TagBuilder{name: "FocalPlaneXResolution"},
@@ -262,7 +276,7 @@ func init() {
 		0xa217: TagBuilder{name: "SensingMethod"},
 		0xa300: TagBuilder{name: "FileSource"},
 		0xa301: TagBuilder{name: "SceneType"},
-		0xa302: TagBuilder{name: "CFAPattern"},
+		0xa302: TagBuilder{name: "CFAPattern", initializer: readCFAPattern},
 		0xa401: TagBuilder{name: "CustomRendered"},
 		0xa402: TagBuilder{name: "ExposureMode"},
 		0xa403: TagBuilder{name: "WhiteBalance"},
@@ -306,21 +320,81 @@ func init() {
 		0x0018: TagBuilder{name: "GPSDestBearing"},
 		0x0019: TagBuilder{name: "GPSDestDistanceRef"},
 		0x001a: TagBuilder{name: "GPSDestDistance"},
-		0x001b: TagBuilder{name: "GPSProcessingMethod"},
-		0x001c: TagBuilder{name: "GPSAreaInformation"},
+		0x001b: TagBuilder{name: "GPSProcessingMethod", initializer: readUserComment},
+		0x001c: TagBuilder{name: "GPSAreaInformation", initializer: readUserComment},
 		0x001d: TagBuilder{name: "GPSDateStamp"},
 		0x001e: TagBuilder{name: "GPSDifferential"},
 	}
 
 	InteropTagMap = map[uint16]TagBuilder{
 		0x0001: TagBuilder{name: "InteroperabilityIndex"},
+		0x0002: TagBuilder{name: "InteroperabilityVersion", initializer: readVersion},
+		0x1000: TagBuilder{name: "RelatedImageFileFormat"},
+		0x1001: TagBuilder{name: "RelatedImageWidth"},
+		0x1002: TagBuilder{name: "RelatedImageLength"},
+	}
+
+	MPFTagMap = map[uint16]TagBuilder{
+		0xb000: TagBuilder{name: "MPFVersion", initializer: readVersion},
+		0xb001: TagBuilder{name: "NumberOfImages"},
+		0xb002: TagBuilder{name: "MPEntry", initializer: readMPEntry},
+		0xb003: TagBuilder{name: "ImageUIDList"},
+		0xb004: TagBuilder{name: "TotalFrames"},
+		0xb101: TagBuilder{name: "MPIndividualNum"},
+		0xb201: TagBuilder{name: "PanOrientation"},
+		0xb202: TagBuilder{name: "PanOverlap_H"},
+		0xb203: TagBuilder{name: "PanOverlap_V"},
+		0xb204: TagBuilder{name: "BaseViewpointNum"},
+		0xb205: TagBuilder{name: "ConvergenceAngle"},
+		0xb206: TagBuilder{name: "BaselineLength"},
+		0xb207: TagBuilder{name: "VerticalDivergence"},
+		0xb208: TagBuilder{name: "AxisDistance_X"},
+		0xb209: TagBuilder{name: "AxisDistance_Y"},
+		0xb20a: TagBuilder{name: "AxisDistance_Z"},
+		0xb20b: TagBuilder{name: "YawAngle"},
+		0xb20c: TagBuilder{name: "PitchAngle"},
+		0xb20d: TagBuilder{name: "RollAngle"},
+	}
+}
+
+// readInteropIfd reads the Interoperability IFD, which is pointed at from the
+// Exif IFD
+func readInteropIfd(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	return readSubIfd(reader, ifd, InteropIfd, raw.Data, []*map[uint16]TagBuilder{&InteropTagMap})
+}
+
+// readSubIfd reads the IFD at the provided address using the provided tag
+// maps, and adds it to the children of ifd.
+func readSubIfd(reader TagReader, ifd *Ifd, id IfdID, address uint32, tagMaps []*map[uint16]TagBuilder) (Tag, bool, error) {
+	chain, err := followIfd(reader, id, address, tagMaps)
+	ifd.Children = append(ifd.Children, chain...)
+	if err != nil {
+		return nil, false, err
+	}
+	return nil, true, nil
+}
+
+// followIfd reads the chain of IFDs at the provided address, and then returns
+// the reader to its position in the parent IFD.
+func followIfd(reader TagReader, id IfdID, address uint32, tagMaps []*map[uint16]TagBuilder) ([]*Ifd, error) {
+	r := reader.GetReader()
+	cur, err := r.GetCurrentOffset()
+	if err != nil {
+		return nil, err
+	}
+	chain, err := reader.ReadIfd(address, id, tagMaps)
+	if seekErr := r.SeekTo(cur); err == nil {
+		err = seekErr
 	}
+	return chain, err
 }
 
-func defaultInitializer(reader TagReader, _ *map[uint16]Tag, name string, raw *RawTagData) (Tag, bool, error) {
+func defaultInitializer(reader TagReader, _ *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
 	dataSize, ok := common.DataFormatSizes[raw.Format]
 	if !ok {
-		return nil, false, errors.New("Do not have matching data format size")
+		// Not a format defined by TIFF; the tag cannot be decoded, but the
+		// remainder of the IFD is still readable.
+		return nil, false, nil
 	}
 	switch raw.Format {
 	case common.ASCIIString:
@@ -333,8 +407,10 @@ func defaultInitializer(reader TagReader, _ *map[uint16]Tag, name string, raw *R
 		return readSingleFloat(reader, name, raw)
 	case common.Srational:
 		return readSignedRational(reader, name, raw)
-	case common.Ubyte, common.Ushort, common.Ulong:
+	case common.Ubyte, common.Ushort, common.Ulong, common.Ifd:
 		return readUnsignedInteger(reader, name, dataSize, raw)
+	case common.Undefined:
+		return readUndefined(reader, name, raw)
 	case common.Urational:
 		return readUnsignedRational(reader, name, raw)
 	}
@@ -1,6 +1,7 @@
 package tags
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/object88/go-image-metadata/common"
@@ -10,19 +11,88 @@ import (
 // Tag is a single name-value pair.
 type Tag interface {
 	fmt.Stringer
+
+	// GetID returns the tag ID
+	GetID() TagID
+
+	// GetName returns the coloquial name of the tag
+	GetName() string
+
+	// GetType returns the data format the tag was stored with
+	GetType() common.DataFormat
+
+	// GetCount returns the number of values held by the tag
+	GetCount() int
+
+	// Uint32s returns the values of an unsigned byte, short or long tag, or nil
+	// for any other data format
+	Uint32s() []uint32
+
+	// Int32s returns the values of a signed byte, short or long tag, or nil for
+	// any other data format
+	Int32s() []int32
+
+	// Rationals returns the values of a signed or unsigned rational tag, or nil
+	// for any other data format
+	Rationals() []Rational
+
+	// Float64s returns the values of a single or double float tag, or nil for
+	// any other data format
+	Float64s() []float64
+
+	// Strings returns the values of an ASCII string tag, or nil for any other
+	// data format
+	Strings() []string
+
+	// Bytes returns the values of an unsigned byte or undefined tag, or nil
+	// for any other data format
+	Bytes() []byte
+
+	// AsInt returns the first value as an integer.  Integer tags are always
+	// convertible; rational tags are convertible if the fraction is whole.
+	AsInt() (int64, bool)
+
+	// AsFloat returns the first value as a float.  Integer, rational and float
+	// tags are convertible, as long as a rational's denominator is not zero.
+	AsFloat() (float64, bool)
+}
+
+// Rational is a fraction, wide enough to hold either a signed or an unsigned
+// rational value without loss
+type Rational struct {
+	Numerator   int64
+	Denominator int64
+}
+
+// Float64 returns the value of the fraction
+func (r Rational) Float64() float64 {
+	return float64(r.Numerator) / float64(r.Denominator)
 }
 
 // TagID is a tag identifier
 type TagID uint16
 
-// TagInitializer takes raw data and returns a Tag
-type TagInitializer func(reader TagReader, foundTags *map[uint16]Tag, name string, raw *RawTagData) (Tag, bool, error)
+// TagInitializer takes raw data and returns a Tag.  The ifd is the IFD which
+// is being read; initializers which follow pointers to other IFDs add them to
+// its children.
+type TagInitializer func(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error)
 
 // TagReader implementations will read over an IFD in a image file, creating
-// Tags and putting them in foundTags.
+// Tags and putting them in an Ifd.
 type TagReader interface {
 	GetReader() reader.Reader
-	ReadIfd(ifdAddress uint32, tags []*map[uint16]TagBuilder, foundTags *map[uint16]Tag)
+
+	// ReadIfd reads the chain of IFDs starting at ifdAddress.  If an error is
+	// returned, the chain contains the IFDs which were read before the failure.
+	// An IFD which has already been read anywhere in the tree, or one nested
+	// too deeply, fails with ErrBadIfdOffset.
+	ReadIfd(ifdAddress uint32, id IfdID, tags []*map[uint16]TagBuilder) ([]*Ifd, error)
+
+	// ReadRebasedIfd reads a chain of IFDs in the provided byte order, whose
+	// offsets are relative to origin rather than to the TIFF header.  origin is
+	// itself relative to the TIFF header.  MakerNotes use this when they do not
+	// share the Exif data's offsets.
+	ReadRebasedIfd(origin uint32, order binary.ByteOrder, ifdAddress uint32, id IfdID, tags []*map[uint16]TagBuilder) ([]*Ifd, error)
 }
 
 // RawTagData contains the data as read from the images byte stream
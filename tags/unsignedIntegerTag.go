@@ -2,9 +2,11 @@ package tags
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 
 	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/reader"
 )
 
 // UnsignedIntegerTag holds an array of integers.  All values are stored as
@@ -30,23 +32,98 @@ func (m *UnsignedIntegerTag) String() string {
 	return buffer.String()
 }
 
+// GetCount returns the number of integers
+func (m *UnsignedIntegerTag) GetCount() int {
+	return len(m.value)
+}
+
+// Uint32s returns the integers
+func (m *UnsignedIntegerTag) Uint32s() []uint32 {
+	return m.value
+}
+
+// Bytes returns the integers as bytes if the tag is an unsigned byte tag
+func (m *UnsignedIntegerTag) Bytes() []byte {
+	if m.GetType() != common.Ubyte {
+		return nil
+	}
+	b := make([]byte, len(m.value))
+	for k, v := range m.value {
+		b[k] = byte(v)
+	}
+	return b
+}
+
+// AsInt returns the first integer
+func (m *UnsignedIntegerTag) AsInt() (int64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return int64(m.value[0]), true
+}
+
+// AsFloat returns the first integer as a float
+func (m *UnsignedIntegerTag) AsFloat() (float64, bool) {
+	if len(m.value) == 0 {
+		return 0, false
+	}
+	return float64(m.value[0]), true
+}
+
 func readUnsignedInteger(reader TagReader, name string, dataSize uint32, raw *RawTagData) (Tag, bool, error) {
-	r := reader.GetReader()
+	v, err := readIntegers(reader.GetReader(), dataSize, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return &UnsignedIntegerTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
+}
+
+// readIntegers reads the 1, 2 or 4 byte integers described by raw, either from
+// the data field itself or from the offset it holds.  Values are returned
+// without sign extension.
+func readIntegers(r reader.Reader, dataSize uint32, raw *RawTagData) ([]uint32, error) {
+	if dataSize != 1 && dataSize != 2 && dataSize != 4 {
+		// Includes formats not defined by TIFF, whose size is 0.
+		return nil, fmt.Errorf("Tag 0x%04x has format %d, which is not an integer format", raw.Tag, raw.Format)
+	}
+	if raw.Count <= 4/dataSize {
+		switch dataSize {
+		case 1:
+			return r.ReadUint8FromUint32(raw.Count, raw.Data)
+		case 2:
+			return r.ReadUint16FromUint32(raw.Count, raw.Data)
+		}
+		return []uint32{raw.Data}[:raw.Count], nil
+	}
+
 	var v []uint32
-	if dataSize*raw.Count > 4 {
-		v = make([]uint32, raw.Count)
-		cur := r.GetCurrentOffset()
-		r.SeekTo(int64(raw.Data))
+	err := readAt(r, raw.Data, uint64(dataSize)*uint64(raw.Count), func() error {
 		// Read off the string of numbers...
-		r.SeekTo(cur)
-	} else {
-		if raw.Format == common.Ubyte {
-			v, _ = r.ReadUint8FromUint32(raw.Count, raw.Data)
-		} else if raw.Format == common.Ushort {
-			v, _ = r.ReadUint16FromUint32(raw.Count, raw.Data)
-		} else if raw.Format == common.Ulong {
-			v = []uint32{raw.Data}
+		v = make([]uint32, raw.Count)
+		for i := uint32(0); i < raw.Count; i++ {
+			var n uint32
+			var err error
+			switch dataSize {
+			case 1:
+				var n8 uint8
+				n8, err = r.ReadUint8()
+				n = uint32(n8)
+			case 2:
+				var n16 uint16
+				n16, err = r.ReadUint16()
+				n = uint32(n16)
+			default:
+				n, err = r.ReadUint32()
+			}
+			if err != nil {
+				return err
+			}
+			v[i] = n
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &UnsignedIntegerTag{BaseTag{name, raw.Tag, raw.Format}, v}, true, nil
+	return v, nil
 }
@@ -0,0 +1,129 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Charset is the character code named by the 8 byte header of a UserComment,
+// GPSProcessingMethod or GPSAreaInformation tag
+type Charset int
+
+const (
+	// UndefinedCharset is named by a header of NULs
+	UndefinedCharset Charset = iota
+
+	// ASCIICharset is ITU-T T.50 IA5
+	ASCIICharset
+
+	// JISCharset is JIS X0208-1990
+	JISCharset
+
+	// UnicodeCharset is UCS-2, in the byte order of the TIFF header
+	UnicodeCharset
+
+	// UnknownCharset is any header not named by the Exif standard
+	UnknownCharset
+)
+
+var charsets = [...]string{
+	"undefined",
+	"ascii",
+	"jis",
+	"unicode",
+	"unknown",
+}
+
+func (c Charset) String() string {
+	return charsets[c]
+}
+
+var charsetHeaders = map[string]Charset{
+	"\x00\x00\x00\x00\x00\x00\x00\x00": UndefinedCharset,
+	"ASCII\x00\x00\x00":                ASCIICharset,
+	"JIS\x00\x00\x00\x00\x00":          JISCharset,
+	"UNICODE\x00":                      UnicodeCharset,
+}
+
+// UserCommentTag holds a comment prefixed by an 8 byte character code header
+type UserCommentTag struct {
+	BytesTag
+	charset Charset
+	text    string
+}
+
+func (m *UserCommentTag) String() string {
+	return m.GetName() + " (" + m.charset.String() + ") [\"" + m.text + "\"]"
+}
+
+// Charset returns the character code named by the header
+func (m *UserCommentTag) Charset() Charset {
+	return m.charset
+}
+
+// Strings returns the decoded comment as a single string
+func (m *UserCommentTag) Strings() []string {
+	return []string{m.text}
+}
+
+func readUserComment(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	if raw.Format != common.Undefined {
+		return defaultInitializer(reader, ifd, name, raw)
+	}
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+
+	charset := UnknownCharset
+	var payload []byte
+	if len(b) >= 8 {
+		if c, ok := charsetHeaders[string(b[:8])]; ok {
+			charset = c
+		}
+		payload = b[8:]
+	}
+
+	var text string
+	switch charset {
+	case UnicodeCharset:
+		text = decodeUcs2(payload, reader.GetReader().GetByteOrder())
+	case JISCharset:
+		// JIS X0208 cannot be transcoded without its tables; the payload is
+		// only used if it happens to be valid UTF-8.
+		if utf8.Valid(payload) {
+			text = string(payload)
+		}
+	default:
+		// Undefined comments are frequently ASCII or UTF-8 in practice
+		if utf8.Valid(payload) {
+			text = string(payload)
+		}
+	}
+	text = strings.TrimRight(text, "\x00 ")
+
+	return &UserCommentTag{BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}, charset, text}, true, nil
+}
+
+// decodeUcs2 decodes UCS-2 text in the provided byte order, unless it starts
+// with a byte order mark.
+func decodeUcs2(b []byte, order binary.ByteOrder) string {
+	if bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
+		order = binary.BigEndian
+		b = b[2:]
+	} else if bytes.HasPrefix(b, []byte{0xff, 0xfe}) {
+		order = binary.LittleEndian
+		b = b[2:]
+	}
+
+	u := make([]uint16, len(b)/2)
+	for k := range u {
+		u[k] = order.Uint16(b[2*k:])
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,28 @@
+package tags
+
+import "github.com/object88/go-image-metadata/common"
+
+// VersionTag holds a 4 byte version, such as ExifVersion's "0231"
+type VersionTag struct {
+	BytesTag
+}
+
+func (m *VersionTag) String() string {
+	return m.GetName() + " [\"" + string(m.value) + "\"]"
+}
+
+// Strings returns the version as a single string
+func (m *VersionTag) Strings() []string {
+	return []string{string(m.value)}
+}
+
+func readVersion(reader TagReader, ifd *Ifd, name string, raw *RawTagData) (Tag, bool, error) {
+	if raw.Format != common.Undefined {
+		return defaultInitializer(reader, ifd, name, raw)
+	}
+	b, err := readRawBytes(reader, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return &VersionTag{BytesTag{BaseTag{name, raw.Tag, raw.Format}, b}}, true, nil
+}
@@ -0,0 +1,240 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+)
+
+// maxIfdDepth limits how deeply IFDs may point at further IFDs, such as IFD0
+// to Exif to Interop
+const maxIfdDepth = 8
+
+// base walks the IFDs of a TIFF byte stream.  It is agnostic of endianness;
+// the embedded reader.Reader is responsible for decoding multi-byte values.
+type base struct {
+	r      reader.Reader
+	logger *slog.Logger
+
+	// visited holds the absolute stream offset of every IFD read into the
+	// current tree, so that a pointer back at any of them cannot cause a loop.
+	// Readers for rebased MakerNote IFDs share it.
+	visited map[int64]bool
+
+	// depth is the number of ReadIfd calls in progress
+	depth int
+}
+
+func (r *base) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *base) ReadPartial(tree *tags.Tree) (int64, error) {
+	// We have already read the first 4 bytes from the header.
+	start, err := r.r.GetReader().Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	start -= 4
+	r.visited = map[int64]bool{}
+	r.depth = 0
+
+	// The next 4 bytes is the address of the first IFD
+	ifdAddress, err := r.r.ReadUint32()
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read address of 1st IFD: %w", err)
+	}
+
+	chain, err := r.ReadIfd(ifdAddress, tags.Ifd0, []*map[uint16]tags.TagBuilder{&tags.TagMap})
+	tree.Ifds = append(tree.Ifds, chain...)
+
+	cur, seekErr := r.r.GetReader().Seek(0, io.SeekCurrent)
+	if err == nil {
+		err = seekErr
+	}
+	return cur - start, err
+}
+
+func (r *base) GetReader() reader.Reader {
+	return r.r
+}
+
+func (r *base) ReadIfd(ifdAddress uint32, id tags.IfdID, tagMaps []*map[uint16]tags.TagBuilder) ([]*tags.Ifd, error) {
+	chain := []*tags.Ifd{}
+	if r.depth >= maxIfdDepth {
+		return chain, fmt.Errorf("%w: %s at 0x%04x is nested more than %d IFDs deep", common.ErrBadIfdOffset, id, ifdAddress, maxIfdDepth)
+	}
+	if r.visited == nil {
+		r.visited = map[int64]bool{}
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+
+	ifdN := -1
+	for {
+		// Loop over all IFD
+		ifdN++
+		ifd := &tags.Ifd{ID: chainID(id, ifdN), Index: ifdN, Offset: ifdAddress}
+
+		r.logger.Debug("Moving to IFD", "ifd", ifd.ID, "index", ifdN, "offset", ifdAddress)
+		err := r.r.SeekTo(int64(ifdAddress))
+		if err != nil {
+			return chain, fmt.Errorf("%w: %s #%d at 0x%04x: %s", common.ErrBadIfdOffset, id, ifdN, ifdAddress, err)
+		}
+		abs, err := r.r.GetReader().Seek(0, io.SeekCurrent)
+		if err != nil {
+			return chain, err
+		}
+		if r.visited[abs] {
+			return chain, fmt.Errorf("%w: %s #%d at 0x%04x has already been read", common.ErrBadIfdOffset, id, ifdN, ifdAddress)
+		}
+		r.visited[abs] = true
+
+		count, err := r.r.ReadUint16()
+		if err != nil {
+			return chain, fmt.Errorf("%w: %s #%d at 0x%04x: %s", common.ErrBadIfdOffset, id, ifdN, ifdAddress, err)
+		}
+		chain = append(chain, ifd)
+		for i := uint16(0); i < count; i++ {
+			raw, err := r.readEntry()
+			if err != nil {
+				return chain, err
+			}
+			next, err := r.r.GetCurrentOffset()
+			if err != nil {
+				return chain, err
+			}
+			t := uint16(raw.Tag)
+
+			matched := false
+			for _, tagMap := range tagMaps {
+				tag, ok := (*tagMap)[t]
+				if !ok {
+					continue
+				}
+
+				initializer := tag.GetInitializer()
+				m, ok, err := initializer(r, ifd, tag.GetName(), raw)
+				if err != nil {
+					// A single bad tag, often in a MakerNote, should not cost the
+					// rest of the IFD.  The tag itself may still have been read,
+					// if only the IFD it points at is bad.
+					r.logger.Debug("Failed to read tag", "ifd", ifd.ID, "index", ifdN, "entry", i, "id", fmt.Sprintf("0x%04x", t), "name", tag.GetName(), "err", err)
+					if err = r.r.SeekTo(next); err != nil {
+						return chain, err
+					}
+					if m == nil {
+						matched = true
+						break
+					}
+				}
+				if !ok {
+					continue
+				}
+
+				if m != nil {
+					r.logger.Debug("Read tag", "ifd", ifd.ID, "index", ifdN, "entry", i, "tag", m)
+					ifd.Tags = append(ifd.Tags, m)
+				}
+
+				matched = true
+				break
+			}
+
+			if !matched && id == tags.MakerNoteIfd {
+				// Vendor tags are mostly undocumented; keep them by ID.
+				m, ok, err := tags.ReadUnknownTag(r, ifd, raw)
+				if err != nil {
+					r.logger.Debug("Failed to read tag", "ifd", ifd.ID, "index", ifdN, "entry", i, "id", fmt.Sprintf("0x%04x", t), "err", err)
+					if err = r.r.SeekTo(next); err != nil {
+						return chain, err
+					}
+				} else if ok && m != nil {
+					ifd.Tags = append(ifd.Tags, m)
+				}
+				matched = true
+			}
+
+			if !matched {
+				// Unknown tag!
+				r.logger.Debug("Unknown tag", "ifd", ifd.ID, "index", ifdN, "entry", i, "id", fmt.Sprintf("0x%04x", t), "format", raw.Format, "count", raw.Count, "data", fmt.Sprintf("0x%08x", raw.Data))
+			}
+		}
+
+		ifdAddress, err = r.r.ReadUint32()
+		if err != nil {
+			return chain, err
+		}
+		if ifdAddress == 0 {
+			r.logger.Debug("End of IFD", "ifd", ifd.ID, "index", ifdN)
+			break
+		}
+	}
+	return chain, nil
+}
+
+func (r *base) ReadRebasedIfd(origin uint32, order binary.ByteOrder, ifdAddress uint32, id tags.IfdID, tagMaps []*map[uint16]tags.TagBuilder) ([]*tags.Ifd, error) {
+	// Find the absolute offset of the TIFF header in the stream
+	rel, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return nil, err
+	}
+	abs, err := r.r.GetReader().Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	start := abs - rel + int64(origin)
+
+	var rr reader.Reader
+	if order == binary.BigEndian {
+		rr = reader.CreateBigEndianReader(r.r.GetReader(), start, r.logger)
+	} else {
+		rr = reader.CreateLittleEndianReader(r.r.GetReader(), start, r.logger)
+	}
+	rebased := &base{r: rr, logger: r.logger, visited: r.visited, depth: r.depth}
+	return rebased.ReadIfd(ifdAddress, id, tagMaps)
+}
+
+// chainID returns the ID for the IFD at the provided position in a chain.  The
+// main chain starts with IFD0 and IFD1, and an MPF chain starts with the MP
+// Index IFD and then the MP Attribute IFD; all other chains share a single ID.
+func chainID(id tags.IfdID, n int) tags.IfdID {
+	if id == tags.MPIndexIfd && n != 0 {
+		return tags.MPAttributeIfd
+	}
+	if id != tags.Ifd0 || n == 0 {
+		return id
+	}
+	if n == 1 {
+		return tags.Ifd1
+	}
+	return tags.ImageIfd
+}
+
+// readEntry reads a single 12 byte IFD entry
+func (r *base) readEntry() (*tags.RawTagData, error) {
+	t, err := r.r.ReadUint16()
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.r.ReadUint16()
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.r.ReadUint32()
+	if err != nil {
+		return nil, err
+	}
+	d, err := r.r.ReadUint32()
+	if err != nil {
+		return nil, err
+	}
+	return &tags.RawTagData{Tag: tags.TagID(t), Format: common.DataFormat(f), Count: c, Data: d}, nil
+}
@@ -0,0 +1,620 @@
+package tiff_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/tags"
+	_ "github.com/object88/go-image-metadata/tiff"
+)
+
+type entry struct {
+	tag    uint16
+	format uint16
+	count  uint32
+	data   uint32
+}
+
+// buildTiff lays out a minimal TIFF stream: header, IFD0, IFD1, an Exif IFD, a
+// GPS IFD and the out-of-line values they point at.
+func buildTiff(order binary.ByteOrder) []byte {
+	var buf bytes.Buffer
+	if order == binary.BigEndian {
+		buf.WriteString("MM")
+	} else {
+		buf.WriteString("II")
+	}
+	binary.Write(&buf, order, uint16(0x2a))
+	binary.Write(&buf, order, uint32(8))
+
+	writeIfd := func(entries []entry, next uint32) {
+		binary.Write(&buf, order, uint16(len(entries)))
+		for _, e := range entries {
+			binary.Write(&buf, order, e.tag)
+			binary.Write(&buf, order, e.format)
+			binary.Write(&buf, order, e.count)
+			if e.format == 3 && e.count == 1 {
+				// Short values are left-justified in the data field.
+				binary.Write(&buf, order, uint16(e.data))
+				binary.Write(&buf, order, uint16(0))
+			} else {
+				binary.Write(&buf, order, e.data)
+			}
+		}
+		binary.Write(&buf, order, next)
+	}
+
+	// IFD0 at 8: 4 entries -> 2 + 4*12 + 4 = 54 bytes; IFD1 at 62
+	writeIfd([]entry{
+		{0x0100, 3, 1, 640},
+		{0x010f, 2, 6, 116},
+		{0x8769, 4, 1, 80},
+		{0x8825, 4, 1, 98},
+	}, 62)
+	// IFD1 at 62: 1 entry -> 18 bytes; Exif IFD at 80
+	writeIfd([]entry{
+		{0x0100, 3, 1, 160},
+	}, 0)
+	// Exif IFD at 80: 1 entry -> 18 bytes; GPS IFD at 98
+	writeIfd([]entry{
+		{0x829d, 5, 1, 122},
+	}, 0)
+	// GPS IFD at 98: 1 entry -> 18 bytes; values at 116
+	writeIfd([]entry{
+		{0x0006, 5, 1, 130},
+	}, 0)
+	buf.WriteString("Nikon\x00")
+	binary.Write(&buf, order, uint32(28))
+	binary.Write(&buf, order, uint32(10))
+	binary.Write(&buf, order, uint32(1234))
+	binary.Write(&buf, order, uint32(10))
+	return buf.Bytes()
+}
+
+func Test_ReadIfd(t *testing.T) {
+	var tcs = []struct {
+		name  string
+		order binary.ByteOrder
+	}{
+		{"Intel", binary.LittleEndian},
+		{"Motorola", binary.BigEndian},
+	}
+
+	// Expected tags, in walk order
+	expected := []struct {
+		ifd   tags.IfdID
+		tagID tags.TagID
+		value string
+	}{
+		{tags.Ifd0, 0x0100, "ImageWidth (unsigned short) [640]"},
+		{tags.Ifd0, 0x010f, "Make [\"Nikon\"]"},
+		{tags.ExifIfd, 0x829d, "FNumber (unsigned rational) [28/10]"},
+		{tags.GpsIfd, 0x0006, "GPSAltitude (unsigned rational) [1234/10]"},
+		{tags.Ifd1, 0x0100, "ImageWidth (unsigned short) [160]"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := buildTiff(tc.order)
+			ir, err := metadata.ReadHeader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+
+			tree := &tags.Tree{}
+			consumed, err := ir.ReadPartial(tree)
+			if err != nil {
+				t.Fatalf("Error while reading tags: %s\n", err)
+			}
+			if consumed <= 0 || consumed > int64(len(b)) {
+				t.Fatalf("Unexpected consumed byte count %d", consumed)
+			}
+			for _, e := range expected {
+				tag, ok := tree.Get(e.ifd, e.tagID)
+				if !ok {
+					t.Fatalf("Missing tag %s 0x%04x", e.ifd, e.tagID)
+				}
+				if tag.String() != e.value {
+					t.Fatalf("Expected tag %s 0x%04x to be '%s'; got '%s'", e.ifd, e.tagID, e.value, tag.String())
+				}
+			}
+
+			n := 0
+			tree.Walk(func(ifd *tags.Ifd, tag tags.Tag) bool {
+				if n >= len(expected) {
+					t.Fatalf("Walked unexpected tag %s %s", ifd.ID, tag)
+				}
+				if ifd.ID != expected[n].ifd || tag.GetID() != expected[n].tagID {
+					t.Fatalf("Expected walk to visit %s 0x%04x at %d; got %s 0x%04x", expected[n].ifd, expected[n].tagID, n, ifd.ID, tag.GetID())
+				}
+				n++
+				return true
+			})
+			if n != len(expected) {
+				t.Fatalf("Expected walk to visit %d tags; got %d", len(expected), n)
+			}
+		})
+	}
+}
+
+func Test_Accessors(t *testing.T) {
+	ir, err := metadata.ReadHeader(bytes.NewReader(buildTiff(binary.BigEndian)))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading tags: %s\n", err)
+	}
+
+	width, _ := tree.Get(tags.Ifd0, 0x0100)
+	if width.GetID() != 0x0100 || width.GetName() != "ImageWidth" || width.GetType() != common.Ushort || width.GetCount() != 1 {
+		t.Fatalf("Unexpected ImageWidth description: %d, %s, %s, %d", width.GetID(), width.GetName(), width.GetType(), width.GetCount())
+	}
+	if v := width.Uint32s(); len(v) != 1 || v[0] != 640 {
+		t.Fatalf("Expected ImageWidth Uint32s to be [640]; got %v", v)
+	}
+	if v, ok := width.AsInt(); !ok || v != 640 {
+		t.Fatalf("Expected ImageWidth AsInt to be 640; got %d, %t", v, ok)
+	}
+	if v := width.Strings(); v != nil {
+		t.Fatalf("Expected ImageWidth Strings to be nil; got %v", v)
+	}
+
+	fNumber, _ := tree.Get(tags.ExifIfd, 0x829d)
+	if v := fNumber.Rationals(); len(v) != 1 || v[0] != (tags.Rational{Numerator: 28, Denominator: 10}) {
+		t.Fatalf("Expected FNumber Rationals to be [28/10]; got %v", v)
+	}
+	if v, ok := fNumber.AsFloat(); !ok || v != 2.8 {
+		t.Fatalf("Expected FNumber AsFloat to be 2.8; got %f, %t", v, ok)
+	}
+	if _, ok := fNumber.AsInt(); ok {
+		t.Fatalf("Expected FNumber AsInt to fail")
+	}
+
+	manufacturer, _ := tree.Get(tags.Ifd0, 0x010f)
+	if v := manufacturer.Strings(); len(v) != 1 || v[0] != "Nikon" {
+		t.Fatalf("Expected Make Strings to be [Nikon]; got %v", v)
+	}
+	if _, ok := manufacturer.AsFloat(); ok {
+		t.Fatalf("Expected Make AsFloat to fail")
+	}
+}
+
+type value struct {
+	tag    uint16
+	format uint16
+	count  uint32
+	data   []interface{}
+
+	// ifd, if set, is laid out after the IFD and pointed at by this entry
+	ifd []value
+}
+
+// buildSingleIfd lays out a TIFF stream with a single IFD in the main chain.
+// Values which fit in 4 bytes are stored in the entry; all others, and any
+// sub-IFDs, are appended after the IFD.
+func buildSingleIfd(order binary.ByteOrder, values []value) []byte {
+	var buf bytes.Buffer
+	if order == binary.BigEndian {
+		buf.WriteString("MM")
+	} else {
+		buf.WriteString("II")
+	}
+	binary.Write(&buf, order, uint16(0x2a))
+	binary.Write(&buf, order, uint32(8))
+	buf.Write(encodeIfd(order, 8, values))
+	return buf.Bytes()
+}
+
+// encodeIfd encodes an IFD which will be located at offset, followed by its
+// out-of-line values.
+func encodeIfd(order binary.ByteOrder, offset uint32, values []value) []byte {
+	var buf, data bytes.Buffer
+	dataStart := offset + uint32(2+12*len(values)+4)
+	binary.Write(&buf, order, uint16(len(values)))
+	for _, v := range values {
+		var encoded bytes.Buffer
+		for _, d := range v.data {
+			binary.Write(&encoded, order, d)
+		}
+
+		binary.Write(&buf, order, v.tag)
+		binary.Write(&buf, order, v.format)
+		binary.Write(&buf, order, v.count)
+		if v.ifd != nil {
+			address := dataStart + uint32(data.Len())
+			binary.Write(&buf, order, address)
+			data.Write(encodeIfd(order, address, v.ifd))
+		} else if encoded.Len() <= 4 {
+			// Left-justify the value in the data field
+			b := make([]byte, 4)
+			copy(b, encoded.Bytes())
+			buf.Write(b)
+		} else {
+			binary.Write(&buf, order, dataStart+uint32(data.Len()))
+			data.Write(encoded.Bytes())
+		}
+		if data.Len()%2 != 0 {
+			data.WriteByte(0)
+		}
+	}
+	binary.Write(&buf, order, uint32(0))
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func Test_IntegerArrays(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		value    value
+		unsigned []uint32
+		signed   []int32
+	}{
+		{"inline bytes", value{0xc612, 1, 4, []interface{}{[]uint8{1, 4, 0, 0}}, nil}, []uint32{1, 4, 0, 0}, nil},
+		{"out-of-line bytes", value{0xc612, 1, 6, []interface{}{[]uint8{1, 2, 3, 4, 5, 6}}, nil}, []uint32{1, 2, 3, 4, 5, 6}, nil},
+		{"inline shorts", value{0x0212, 3, 2, []interface{}{[]uint16{2, 1}}, nil}, []uint32{2, 1}, nil},
+		{"out-of-line shorts", value{0x0102, 3, 3, []interface{}{[]uint16{8, 8, 0x1234}}, nil}, []uint32{8, 8, 0x1234}, nil},
+		{"inline long", value{0x0111, 4, 1, []interface{}{uint32(0x12345678)}, nil}, []uint32{0x12345678}, nil},
+		{"out-of-line longs", value{0x0111, 4, 3, []interface{}{[]uint32{8, 1000, 70000}}, nil}, []uint32{8, 1000, 70000}, nil},
+		{"inline signed bytes", value{0xc61b, 6, 3, []interface{}{[]int8{-1, 0, 127}}, nil}, nil, []int32{-1, 0, 127}},
+		{"out-of-line signed bytes", value{0xc61b, 6, 5, []interface{}{[]int8{-128, -1, 0, 1, 127}}, nil}, nil, []int32{-128, -1, 0, 1, 127}},
+		{"inline signed shorts", value{0xc61b, 8, 2, []interface{}{[]int16{-5, 7}}, nil}, nil, []int32{-5, 7}},
+		{"out-of-line signed shorts", value{0xc61b, 8, 3, []interface{}{[]int16{-1, 2, -300}}, nil}, nil, []int32{-1, 2, -300}},
+		{"inline signed long", value{0xc61b, 9, 1, []interface{}{int32(-2)}, nil}, nil, []int32{-2}},
+		{"out-of-line signed longs", value{0xc61b, 9, 2, []interface{}{[]int32{-70000, 5}}, nil}, nil, []int32{-70000, 5}},
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, tc := range tcs {
+			t.Run(order.String()+" "+tc.name, func(t *testing.T) {
+				b := buildSingleIfd(order, []value{tc.value, {0x0100, 3, 1, []interface{}{uint16(640)}, nil}})
+				ir, err := metadata.ReadHeader(bytes.NewReader(b))
+				if err != nil {
+					t.Fatalf("Error while reading header: %s\n", err)
+				}
+				tree, err := ir.Read()
+				if err != nil {
+					t.Fatalf("Error while reading tags: %s\n", err)
+				}
+
+				tag, ok := tree.Get(tags.Ifd0, tags.TagID(tc.value.tag))
+				if !ok {
+					t.Fatalf("Missing tag 0x%04x", tc.value.tag)
+				}
+				if tc.unsigned != nil && !reflect.DeepEqual(tag.Uint32s(), tc.unsigned) {
+					t.Fatalf("Expected %v; got %v", tc.unsigned, tag.Uint32s())
+				}
+				if tc.signed != nil && !reflect.DeepEqual(tag.Int32s(), tc.signed) {
+					t.Fatalf("Expected %v; got %v", tc.signed, tag.Int32s())
+				}
+
+				// The entry following the array must still be read correctly
+				width, ok := tree.Get(tags.Ifd0, 0x0100)
+				if v, _ := width.AsInt(); !ok || v != 640 {
+					t.Fatalf("Expected ImageWidth to be 640; got %s", width)
+				}
+			})
+		}
+	}
+}
+
+func Test_IntegerArrayPastEnd(t *testing.T) {
+	b := buildSingleIfd(binary.LittleEndian, []value{{0x0111, 4, 3, []interface{}{[]uint32{8, 1000, 70000}}, nil}})
+	// Claim far more values than the stream holds
+	binary.LittleEndian.PutUint32(b[8+2+4:], 0x10000000)
+
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Expected the bad tag to be skipped; got '%v'", err)
+	}
+	if tag, ok := tree.Get(tags.Ifd0, 0x0111); ok {
+		t.Fatalf("Expected no StripOffsets tag; got %s", tag)
+	}
+}
+
+func Test_InvalidIntegerFormat(t *testing.T) {
+	// A SubIFDs tag with a format outside TIFF, whose size is 0, and a count
+	// far beyond what fits in the data field
+	b := []byte("II*\x00\x08\x00\x00\x00\x01\x00\x4a\x01\x30\x30\x30\x30\x30\x30\x30\x30\x30\x30")
+
+	var tcs = []struct {
+		name     string
+		b        []byte
+		expected error
+	}{
+		{"without next IFD offset", b, metadata.ErrTruncatedSegment},
+		{"with next IFD offset", append(b[:len(b):len(b)], 0, 0, 0, 0), nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(tc.b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error %v; got %v", tc.expected, err)
+			}
+			if err != nil {
+				return
+			}
+			if tag, ok := tree.Get(tags.Ifd0, 0x014a); ok {
+				t.Fatalf("Expected no SubIFDs tag; got %s", tag)
+			}
+		})
+	}
+}
+
+func Test_IfdLoops(t *testing.T) {
+	le := binary.LittleEndian
+	ifd := func(b []byte, tag uint16, target uint32) []byte {
+		b = le.AppendUint16(b, 1)
+		b = le.AppendUint16(b, tag)
+		b = le.AppendUint16(b, 4)
+		b = le.AppendUint32(b, 1)
+		b = le.AppendUint32(b, target)
+		return le.AppendUint32(b, 0)
+	}
+	header := []byte{'I', 'I', 0x2a, 0, 8, 0, 0, 0}
+
+	var tcs = []struct {
+		name string
+		b    []byte
+	}{
+		{"Exif IFD points at itself", ifd(ifd(header, 0x8769, 26), 0x8769, 26)},
+		{"Exif IFD points at IFD0", ifd(ifd(header, 0x8769, 26), 0x8769, 8)},
+		{"Interop IFD points at Exif IFD", ifd(ifd(header, 0x8769, 26), 0xa005, 26)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(tc.b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if err != nil {
+				t.Fatalf("Expected the looping tag to be skipped; got '%v'", err)
+			}
+			if len(tree.Ifds) != 1 || len(tree.Ifds[0].Children) != 1 || tree.Ifds[0].Children[0].ID != tags.ExifIfd {
+				t.Fatalf("Expected IFD0 with a single Exif IFD")
+			}
+		})
+	}
+}
+
+func Test_Undefined(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		unicode := []interface{}{[]byte("UNICODE\x00")}
+		for _, r := range "Grüße" {
+			unicode = append(unicode, uint16(r))
+		}
+
+		b := buildSingleIfd(order, []value{
+			{0x8769, 4, 1, nil, []value{
+				{0x9000, 7, 4, []interface{}{[]byte("0231")}, nil},
+				{0x9101, 7, 4, []interface{}{[]byte{1, 2, 3, 0}}, nil},
+				{0x9286, 7, 18, []interface{}{[]byte("ASCII\x00\x00\x00Hello\x00    ")}, nil},
+				{0xa300, 7, 1, []interface{}{[]byte{3}}, nil},
+				{0xa302, 7, 8, []interface{}{uint16(2), uint16(2), []byte{0, 1, 1, 2}}, nil},
+				{0xa20c, 7, 20, []interface{}{make([]byte, 20)}, nil},
+			}},
+			{0x8825, 4, 1, nil, []value{
+				{0x001b, 7, 18, unicode, nil},
+			}},
+		})
+
+		t.Run(order.String(), func(t *testing.T) {
+			ir, err := metadata.ReadHeader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if err != nil {
+				t.Fatalf("Error while reading tags: %s\n", err)
+			}
+
+			expected := []struct {
+				ifd     tags.IfdID
+				tagID   tags.TagID
+				value   string
+				strings []string
+				bytes   []byte
+			}{
+				{tags.ExifIfd, 0x9000, "ExifVersion [\"0231\"]", []string{"0231"}, []byte("0231")},
+				{tags.ExifIfd, 0x9101, "ComponentsConfiguration (undefined) [01 02 03 00]", nil, []byte{1, 2, 3, 0}},
+				{tags.ExifIfd, 0x9286, "UserComment (ascii) [\"Hello\"]", []string{"Hello"}, nil},
+				{tags.ExifIfd, 0xa300, "FileSource (undefined) [03]", nil, []byte{3}},
+				{tags.ExifIfd, 0xa302, "CFAPattern [[R G] [G B]]", nil, nil},
+				{tags.ExifIfd, 0xa20c, "SpatialFrequencyResponse (undefined) [00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 ...] (20 bytes)", nil, nil},
+				{tags.GpsIfd, 0x001b, "GPSProcessingMethod (unicode) [\"Grüße\"]", []string{"Grüße"}, nil},
+			}
+			for _, e := range expected {
+				tag, ok := tree.Get(e.ifd, e.tagID)
+				if !ok {
+					t.Fatalf("Missing tag %s 0x%04x", e.ifd, e.tagID)
+				}
+				if tag.String() != e.value {
+					t.Fatalf("Expected tag %s 0x%04x to be '%s'; got '%s'", e.ifd, e.tagID, e.value, tag.String())
+				}
+				if e.strings != nil && !reflect.DeepEqual(tag.Strings(), e.strings) {
+					t.Fatalf("Expected tag %s 0x%04x strings to be %v; got %v", e.ifd, e.tagID, e.strings, tag.Strings())
+				}
+				if e.bytes != nil && !reflect.DeepEqual(tag.Bytes(), e.bytes) {
+					t.Fatalf("Expected tag %s 0x%04x bytes to be %v; got %v", e.ifd, e.tagID, e.bytes, tag.Bytes())
+				}
+			}
+
+			cfa, _ := tree.Get(tags.ExifIfd, 0xa302)
+			pattern := cfa.(*tags.CFAPatternTag).Pattern()
+			if !reflect.DeepEqual(pattern, [][]uint8{{0, 1}, {1, 2}}) {
+				t.Fatalf("Unexpected CFA pattern %v", pattern)
+			}
+		})
+	}
+}
+
+func Test_ASCIIStrings(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		data     string
+		expected []string
+		utf8     bool
+	}{
+		{"inline", "N\x00", []string{"N"}, false},
+		{"inline without terminator", "ABCD", []string{"ABCD"}, false},
+		{"out-of-line", "Nikon\x00", []string{"Nikon"}, false},
+		{"out-of-line without terminator", "Hello", []string{"Hello"}, false},
+		{"padded", "Canon\x00\x00\x00\x00", []string{"Canon"}, false},
+		{"multiple strings", "one\x00two\x00three\x00", []string{"one", "two", "three"}, false},
+		{"empty", "\x00", []string{}, false},
+		{"utf-8", "Café\x00", []string{"Café"}, true},
+		{"latin-1", "Caf\xe9\x00", []string{"Caf\xe9"}, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := buildSingleIfd(binary.BigEndian, []value{
+				{0x010e, 2, uint32(len(tc.data)), []interface{}{[]byte(tc.data)}, nil},
+				{0x0100, 3, 1, []interface{}{uint16(640)}, nil},
+			})
+			ir, err := metadata.ReadHeader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if err != nil {
+				t.Fatalf("Error while reading tags: %s\n", err)
+			}
+
+			tag, ok := tree.Get(tags.Ifd0, 0x010e)
+			if !ok {
+				t.Fatalf("Missing ImageDescription")
+			}
+			if !reflect.DeepEqual(tag.Strings(), tc.expected) {
+				t.Fatalf("Expected %q; got %q", tc.expected, tag.Strings())
+			}
+			if tag.(*tags.StringTag).IsUTF8() != tc.utf8 {
+				t.Fatalf("Expected IsUTF8 to be %t", tc.utf8)
+			}
+
+			width, ok := tree.Get(tags.Ifd0, 0x0100)
+			if v, _ := width.AsInt(); !ok || v != 640 {
+				t.Fatalf("Expected ImageWidth to be 640; got %s", width)
+			}
+		})
+	}
+}
+
+func Test_Interop(t *testing.T) {
+	b := buildSingleIfd(binary.LittleEndian, []value{
+		{0x8769, 4, 1, nil, []value{
+			{0xa001, 3, 1, []interface{}{uint16(0xffff)}, nil},
+			{0xa005, 4, 1, nil, []value{
+				{0x0001, 2, 4, []interface{}{[]byte("R03\x00")}, nil},
+				{0x0002, 7, 4, []interface{}{[]byte("0100")}, nil},
+			}},
+		}},
+	})
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading tags: %s\n", err)
+	}
+
+	interop := tree.Find(tags.InteropIfd)
+	if len(interop) != 1 {
+		t.Fatalf("Expected 1 Interop IFD; got %d", len(interop))
+	}
+	index, ok := interop[0].Get(0x0001)
+	if !ok || !reflect.DeepEqual(index.Strings(), []string{"R03"}) {
+		t.Fatalf("Expected InteroperabilityIndex to be R03; got %v", index)
+	}
+	version, ok := tree.Get(tags.InteropIfd, 0x0002)
+	if !ok || !reflect.DeepEqual(version.Strings(), []string{"0100"}) {
+		t.Fatalf("Expected InteroperabilityVersion to be 0100; got %v", version)
+	}
+	if _, ok := tree.Get(tags.ExifIfd, 0x0001); ok {
+		t.Fatalf("Expected InteroperabilityIndex to be scoped to the Interop IFD")
+	}
+}
+
+// makerNoteIfd encodes an IFD of short values, which are all stored inline
+func makerNoteIfd(order binary.ByteOrder, entries map[uint16]uint16) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, order, uint16(len(entries)))
+	for tag := uint16(0); tag < 0x100; tag++ {
+		v, ok := entries[tag]
+		if !ok {
+			continue
+		}
+		binary.Write(&buf, order, tag)
+		binary.Write(&buf, order, uint16(3))
+		binary.Write(&buf, order, uint32(1))
+		binary.Write(&buf, order, v)
+		binary.Write(&buf, order, uint16(0))
+	}
+	binary.Write(&buf, order, uint32(0))
+	return buf.Bytes()
+}
+
+func Test_MakerNote(t *testing.T) {
+	order := binary.LittleEndian
+	nikon := append([]byte("Nikon\x00\x02\x10\x00\x00MM\x00\x2a\x00\x00\x00\x08"), makerNoteIfd(binary.BigEndian, map[uint16]uint16{0x0002: 200})...)
+	apple := append([]byte("Apple iOS\x00\x00\x01MM"), makerNoteIfd(binary.BigEndian, map[uint16]uint16{0x0008: 5})...)
+	canon := makerNoteIfd(order, map[uint16]uint16{0x0006: 7})
+
+	var tcs = []struct {
+		name  string
+		note  []byte
+		tagID tags.TagID
+		value uint32
+	}{
+		{"Nikon embedded TIFF header", nikon, 0x0002, 200},
+		{"Apple relative offsets", apple, 0x0008, 5},
+		{"Canon without a prefix", canon, 0x0006, 7},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := buildSingleIfd(order, []value{
+				{0x8769, 4, 1, nil, []value{
+					{0x927c, 7, uint32(len(tc.note)), []interface{}{tc.note}, nil},
+				}},
+			})
+			ir, err := metadata.ReadHeader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error while reading header: %s\n", err)
+			}
+			tree, err := ir.Read()
+			if err != nil {
+				t.Fatalf("Error while reading tags: %s\n", err)
+			}
+
+			if tag, ok := tree.Get(tags.ExifIfd, 0x927c); !ok || !bytes.Equal(tag.Bytes(), tc.note) {
+				t.Fatalf("Expected raw MakerNote in the Exif IFD")
+			}
+			tag, ok := tree.Get(tags.MakerNoteIfd, tc.tagID)
+			if !ok {
+				t.Fatalf("Missing MakerNote tag 0x%04x", tc.tagID)
+			}
+			if v := tag.Uint32s(); len(v) != 1 || v[0] != tc.value {
+				t.Fatalf("Expected MakerNote tag 0x%04x to be %d; got %s", tc.tagID, tc.value, tag)
+			}
+		})
+	}
+}
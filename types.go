@@ -17,5 +17,5 @@ type markerSegment struct {
 // stream.  If the byte stream conforms to the shape readable by the
 // implementor, an ImageReader should be returned.  An error should only be
 // returned if there is a problem reading the byte stream, not in the case of
-// non-conformity.
-type CheckHeader func(reader io.ReadSeeker) (ImageReader, error)
+// non-conformity.  Implementations should log through options.Logger.
+type CheckHeader func(reader io.ReadSeeker, options *Options) (ImageReader, error)
@@ -0,0 +1,209 @@
+package webp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// Format is the kind of the first image chunk
+type Format int
+
+const (
+	// Lossy images have a "VP8 " chunk
+	Lossy Format = iota
+
+	// Lossless images have a "VP8L" chunk
+	Lossless
+
+	// Extended images have a "VP8X" chunk, followed by image or animation
+	// chunks
+	Extended
+)
+
+var formats = [...]string{
+	"lossy",
+	"lossless",
+	"extended",
+}
+
+func (f Format) String() string {
+	return formats[f]
+}
+
+// Header describes the canvas
+type Header struct {
+	Format Format
+
+	// Width and Height are the canvas size from the VP8X chunk, or the frame
+	// size of a simple lossy or lossless image
+	Width  uint32
+	Height uint32
+
+	// Alpha is true if the image has an alpha channel
+	Alpha bool
+
+	// Animated is true if the VP8X chunk has the animation flag
+	Animated bool
+
+	// ICC, EXIF and XMP are the VP8X flags for the presence of the metadata
+	// chunks
+	ICC  bool
+	EXIF bool
+	XMP  bool
+}
+
+// Frame is an ANMF chunk
+type Frame struct {
+	X      uint32
+	Y      uint32
+	Width  uint32
+	Height uint32
+
+	Duration time.Duration
+
+	// Blend is false if the frame replaces the canvas, rather than being
+	// alpha-blended onto it
+	Blend bool
+
+	// Dispose is true if the frame's area is cleared to the background color
+	// before the next frame
+	Dispose bool
+}
+
+// Animation is the content of the ANIM and ANMF chunks
+type Animation struct {
+	// BackgroundColor is in [Blue, Green, Red, Alpha] byte order
+	BackgroundColor uint32
+
+	// LoopCount is the number of times to loop, or 0 to loop forever
+	LoopCount uint16
+
+	Frames []Frame
+}
+
+// Duration returns the sum of the frame durations
+func (a *Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range a.Frames {
+		d += f.Duration
+	}
+	return d
+}
+
+// Header returns the canvas description, or nil if there was no image chunk
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Animation returns the animation, or nil if there was no ANIM chunk
+func (r *Reader) Animation() *Animation {
+	return r.animation
+}
+
+// readChunkHeader reads the fixed-size start of a chunk
+func (r *Reader) readChunkHeader(name string, length uint32, size int) ([]byte, error) {
+	if int64(length) < int64(size) {
+		return nil, fmt.Errorf("%w: %s chunk is %d bytes; expected %d", common.ErrTruncatedSegment, name, length, size)
+	}
+	return r.r.ReadBytes(size)
+}
+
+func le24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+func (r *Reader) readVP8X(length uint32) error {
+	b, err := r.readChunkHeader("VP8X", length, 10)
+	if err != nil {
+		return err
+	}
+	r.header = &Header{
+		Format:   Extended,
+		Width:    le24(b[4:]) + 1,
+		Height:   le24(b[7:]) + 1,
+		ICC:      b[0]&0x20 != 0,
+		Alpha:    b[0]&0x10 != 0,
+		EXIF:     b[0]&0x08 != 0,
+		XMP:      b[0]&0x04 != 0,
+		Animated: b[0]&0x02 != 0,
+	}
+	r.options.Logger.Debug("Read VP8X", "width", r.header.Width, "height", r.header.Height, "animated", r.header.Animated)
+	return nil
+}
+
+func (r *Reader) readVP8(length uint32) error {
+	if r.header != nil {
+		// The canvas is described by VP8X
+		return nil
+	}
+	b, err := r.readChunkHeader("VP8 ", length, 10)
+	if err != nil {
+		return err
+	}
+	if b[3] != 0x9d || b[4] != 0x01 || b[5] != 0x2a {
+		return fmt.Errorf("VP8 chunk has no key frame start code")
+	}
+	r.header = &Header{
+		Format: Lossy,
+		Width:  uint32(b[6]) | uint32(b[7]&0x3f)<<8,
+		Height: uint32(b[8]) | uint32(b[9]&0x3f)<<8,
+	}
+	return nil
+}
+
+func (r *Reader) readVP8L(length uint32) error {
+	if r.header != nil {
+		// The canvas is described by VP8X
+		return nil
+	}
+	b, err := r.readChunkHeader("VP8L", length, 5)
+	if err != nil {
+		return err
+	}
+	if b[0] != 0x2f {
+		return fmt.Errorf("VP8L chunk has signature 0x%02x", b[0])
+	}
+	bits := uint32(b[1]) | uint32(b[2])<<8 | uint32(b[3])<<16 | uint32(b[4])<<24
+	r.header = &Header{
+		Format: Lossless,
+		Width:  bits&0x3fff + 1,
+		Height: (bits>>14)&0x3fff + 1,
+		Alpha:  bits&(1<<28) != 0,
+	}
+	return nil
+}
+
+func (r *Reader) readANIM(length uint32) error {
+	b, err := r.readChunkHeader("ANIM", length, 6)
+	if err != nil {
+		return err
+	}
+	if r.animation == nil {
+		r.animation = &Animation{}
+	}
+	r.animation.BackgroundColor = uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+	r.animation.LoopCount = uint16(b[4]) | uint16(b[5])<<8
+	return nil
+}
+
+func (r *Reader) readANMF(length uint32) error {
+	b, err := r.readChunkHeader("ANMF", length, 16)
+	if err != nil {
+		return err
+	}
+	if r.animation == nil {
+		r.animation = &Animation{}
+	}
+	r.animation.Frames = append(r.animation.Frames, Frame{
+		X:        le24(b[0:]) * 2,
+		Y:        le24(b[3:]) * 2,
+		Width:    le24(b[6:]) + 1,
+		Height:   le24(b[9:]) + 1,
+		Duration: time.Duration(le24(b[12:])) * time.Millisecond,
+		Blend:    b[15]&0x02 == 0,
+		Dispose:  b[15]&0x01 != 0,
+	})
+	return nil
+}
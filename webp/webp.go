@@ -0,0 +1,175 @@
+package webp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/common"
+	"github.com/object88/go-image-metadata/icc"
+	"github.com/object88/go-image-metadata/reader"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/xmp"
+
+	// EXIF chunks are TIFF structures
+	_ "github.com/object88/go-image-metadata/tiff"
+)
+
+func init() {
+	metadata.RegisterHeaderCheck(CheckHeader)
+}
+
+// Reader understands a WebP byte stream
+type Reader struct {
+	r       reader.Reader
+	options *metadata.Options
+
+	// end is the offset of the end of the RIFF chunk
+	end int64
+
+	header     *Header
+	animation  *Animation
+	iccProfile *icc.Profile
+	xmp        *xmp.Packet
+}
+
+// CheckHeader checks the byte stream to see if it is a RIFF container with
+// the WEBP form type
+func CheckHeader(r io.ReadSeeker, options *metadata.Options) (metadata.ImageReader, error) {
+	cur, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 12)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a WebP
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return nil, nil
+	}
+	options.Logger.Debug("Matched WebP header", "offset", cur)
+	size := int64(b[4]) | int64(b[5])<<8 | int64(b[6])<<16 | int64(b[7])<<24
+	return &Reader{r: reader.CreateLittleEndianReader(r, cur, options.Logger), options: options, end: 8 + size}, nil
+}
+
+func (r *Reader) Read() (*tags.Tree, error) {
+	tree := &tags.Tree{}
+	_, err := r.ReadPartial(tree)
+	return tree, err
+}
+
+func (r *Reader) ReadPartial(tree *tags.Tree) (int64, error) {
+	start, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.r.GetSize()
+	if err != nil {
+		return 0, err
+	}
+	end := min(r.end, size)
+
+	// Loop over chunks
+	for {
+		cur, err := r.r.GetCurrentOffset()
+		if err != nil {
+			return 0, err
+		}
+		if cur+8 > end {
+			break
+		}
+
+		kind, err := r.r.ReadBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		length, err := r.r.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		name := string(kind)
+		r.options.Logger.Debug("Read chunk", "type", name, "length", length, "offset", cur)
+
+		next := cur + 8 + int64(length) + int64(length&1)
+		if cur+8+int64(length) > size {
+			return 0, fmt.Errorf("%w: %s chunk at offset %d with length %d", common.ErrTruncatedSegment, name, cur, length)
+		}
+
+		if err = r.readChunk(name, length, tree); err != nil {
+			return 0, err
+		}
+		if err = r.r.SeekTo(min(next, size)); err != nil {
+			return 0, err
+		}
+	}
+
+	cur, err := r.r.GetCurrentOffset()
+	if err != nil {
+		return 0, err
+	}
+	return cur - start, nil
+}
+
+func (r *Reader) readChunk(name string, length uint32, tree *tags.Tree) error {
+	switch name {
+	case "VP8X":
+		return r.readVP8X(length)
+	case "VP8 ":
+		return r.readVP8(length)
+	case "VP8L":
+		return r.readVP8L(length)
+	case "ANIM":
+		return r.readANIM(length)
+	case "ANMF":
+		return r.readANMF(length)
+	case "ICCP":
+		b, err := r.r.ReadBytes(int(length))
+		if err != nil {
+			return err
+		}
+		// A malformed profile costs only this chunk
+		if r.iccProfile, err = icc.Parse(b); err != nil {
+			r.options.Logger.Debug("Ignoring ICCP chunk", "err", err)
+		}
+	case "EXIF":
+		b, err := r.r.ReadBytes(int(length))
+		if err != nil {
+			return err
+		}
+		// Some writers keep the JPEG APP1 identifier
+		b = bytes.TrimPrefix(b, []byte("Exif\x00\x00"))
+		r1, err := metadata.ReadHeader(bytes.NewReader(b), metadata.WithOptions(r.options))
+		if err != nil {
+			return fmt.Errorf("Failed to read EXIF chunk: %w", err)
+		}
+		if _, err = r1.ReadPartial(tree); err != nil {
+			return fmt.Errorf("Failed to read EXIF chunk: %w", err)
+		}
+	case "XMP ":
+		b, err := r.r.ReadBytes(int(length))
+		if err != nil {
+			return err
+		}
+		// A malformed packet costs only this chunk
+		if r.xmp, err = xmp.Parse(b); err != nil {
+			r.options.Logger.Debug("Ignoring XMP chunk", "err", err)
+		}
+	}
+	return nil
+}
+
+// ICCProfile returns the profile from the ICCP chunk, or nil if there was none
+func (r *Reader) ICCProfile() *icc.Profile {
+	return r.iccProfile
+}
+
+// XMP returns the packet from the XMP chunk, or nil if there was none
+func (r *Reader) XMP() *xmp.Packet {
+	return r.xmp
+}
@@ -0,0 +1,128 @@
+package webp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	metadata "github.com/object88/go-image-metadata"
+	"github.com/object88/go-image-metadata/tags"
+	"github.com/object88/go-image-metadata/webp"
+)
+
+// chunk encodes a RIFF chunk, padded to an even size
+func chunk(kind string, data []byte) []byte {
+	b := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	b = append(b, data...)
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// buildWebp wraps the chunks in a RIFF WEBP container
+func buildWebp(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	b := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	return append(b, body...)
+}
+
+func readWebp(t *testing.T, b []byte) (*webp.Reader, *tags.Tree) {
+	ir, err := metadata.ReadHeader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error while reading header: %s\n", err)
+	}
+	tree, err := ir.Read()
+	if err != nil {
+		t.Fatalf("Error while reading chunks: %s\n", err)
+	}
+	return ir.(*webp.Reader), tree
+}
+
+func Test_Simple(t *testing.T) {
+	var tcs = []struct {
+		name     string
+		chunk    []byte
+		expected webp.Header
+	}{
+		{"lossy", chunk("VP8 ", []byte{0x50, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01, 0xff}), webp.Header{Format: webp.Lossy, Width: 640, Height: 480}},
+		{"lossless", chunk("VP8L", []byte{0x2f, 0x7f, 0xc2, 0x77, 0x10, 0xff}), webp.Header{Format: webp.Lossless, Width: 640, Height: 480, Alpha: true}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := readWebp(t, buildWebp(tc.chunk))
+			if h := r.Header(); h == nil || *h != tc.expected {
+				t.Fatalf("Expected header %+v; got %+v", tc.expected, h)
+			}
+		})
+	}
+}
+
+func Test_Extended(t *testing.T) {
+	vp8x := []byte{0x3e, 0, 0, 0, 0x7f, 0x07, 0x00, 0x37, 0x04, 0x00}
+	anim := []byte{0xff, 0xff, 0xff, 0xff, 3, 0}
+	frame := func(duration uint32, flags byte) []byte {
+		b := make([]byte, 16)
+		b[0], b[3] = 5, 10
+		b[6], b[9] = 99, 49
+		b[12], b[13], b[14] = byte(duration), byte(duration>>8), byte(duration>>16)
+		b[15] = flags
+		return append(b, chunk("VP8L", []byte{0x2f, 0x63, 0x40, 0x0c, 0x00})...)
+	}
+	exif := []byte("Exif\x00\x00II\x2a\x00\x08\x00\x00\x00\x01\x00\x0f\x01\x02\x00\x06\x00\x00\x00\x1a\x00\x00\x00\x00\x00\x00\x00Canon\x00")
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="5"/></rdf:RDF></x:xmpmeta>`
+
+	r, tree := readWebp(t, buildWebp(
+		chunk("VP8X", vp8x),
+		chunk("ANIM", anim),
+		chunk("ANMF", frame(100, 0x00)),
+		chunk("ANMF", frame(250, 0x03)),
+		chunk("EXIF", exif),
+		chunk("XMP ", []byte(packet)),
+	))
+
+	expected := webp.Header{Format: webp.Extended, Width: 1920, Height: 1080, Alpha: true, Animated: true, ICC: true, EXIF: true, XMP: true}
+	if h := r.Header(); h == nil || *h != expected {
+		t.Fatalf("Expected header %+v; got %+v", expected, h)
+	}
+
+	a := r.Animation()
+	if a == nil || a.LoopCount != 3 || len(a.Frames) != 2 {
+		t.Fatalf("Expected 2 frames looping 3 times; got %+v", a)
+	}
+	if a.Duration() != 350*time.Millisecond {
+		t.Fatalf("Expected duration 350ms; got %s", a.Duration())
+	}
+	f := a.Frames[1]
+	if f.X != 10 || f.Y != 20 || f.Width != 100 || f.Height != 50 || f.Blend || !f.Dispose {
+		t.Fatalf("Expected 100x50 frame at 10,20 without blending, disposed; got %+v", f)
+	}
+
+	if v, ok := tree.Get(tags.Ifd0, 0x010f); !ok || v.Strings()[0] != "Canon" {
+		t.Fatalf("Expected Exif Make 'Canon'; got %v", v)
+	}
+	if p := r.XMP(); p == nil {
+		t.Fatalf("Expected XMP packet; got nil")
+	} else if n, ok := p.Get("http://ns.adobe.com/xap/1.0/", "Rating"); !ok || n.Value != "5" {
+		t.Fatalf("Expected xmp:Rating 5; got %v", n)
+	}
+}
+
+func Test_MalformedChunks(t *testing.T) {
+	r, _ := readWebp(t, buildWebp(
+		chunk("ICCP", []byte("not a profile")),
+		chunk("XMP ", []byte("<x:xmpmeta><unclosed")),
+		chunk("VP8L", []byte{0x2f, 0x7f, 0xc2, 0x77, 0x10, 0xff}),
+	))
+	if r.ICCProfile() != nil || r.XMP() != nil {
+		t.Fatalf("Expected malformed ICCP and XMP chunks to be dropped")
+	}
+	if h := r.Header(); h == nil || h.Width != 640 {
+		t.Fatalf("Expected the image chunk after the malformed ones to be read; got %+v", h)
+	}
+}
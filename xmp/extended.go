@@ -0,0 +1,109 @@
+package xmp
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/object88/go-image-metadata/common"
+)
+
+// ExtendedChunkHeaderSize is the size of the GUID, full length, and offset
+// which precede the data of each Extended XMP chunk
+const ExtendedChunkHeaderSize = 32 + 4 + 4
+
+// maxExtendedLength bounds the full length claimed by Extended XMP chunks
+const maxExtendedLength = 64 << 20
+
+type chunk struct {
+	offset uint32
+	data   []byte
+}
+
+type extendedPacket struct {
+	length uint32
+	chunks []chunk
+}
+
+// Extended collects the chunks of Extended XMP packets, which are split
+// across several segments when they do not fit in one.  Chunks are keyed by
+// the GUID of the packet, which is the MD5 digest of the full packet.
+type Extended struct {
+	packets map[string]*extendedPacket
+}
+
+// AddChunk records one Extended XMP chunk, starting with its 32 byte GUID
+func (e *Extended) AddChunk(b []byte) error {
+	if len(b) < ExtendedChunkHeaderSize {
+		return fmt.Errorf("%w: Extended XMP chunk is %d bytes", common.ErrTruncatedSegment, len(b))
+	}
+	guid := strings.ToUpper(string(b[:32]))
+	length := binary.BigEndian.Uint32(b[32:36])
+	offset := binary.BigEndian.Uint32(b[36:40])
+
+	if e.packets == nil {
+		e.packets = map[string]*extendedPacket{}
+	}
+	p, ok := e.packets[guid]
+	if !ok {
+		p = &extendedPacket{length: length}
+		e.packets[guid] = p
+	}
+	p.chunks = append(p.chunks, chunk{offset: offset, data: b[ExtendedChunkHeaderSize:]})
+	return nil
+}
+
+// assemble returns the full packet with the GUID, if its chunks cover it
+// completely, and its digest matches
+func (e *Extended) assemble(guid string) ([]byte, error) {
+	p, ok := e.packets[strings.ToUpper(guid)]
+	if !ok {
+		return nil, fmt.Errorf("No Extended XMP chunks with GUID %s", guid)
+	}
+
+	// The length is only a claim; check it against what was received before
+	// allocating for it.
+	received := uint64(0)
+	for _, c := range p.chunks {
+		received += uint64(len(c.data))
+	}
+	if p.length > maxExtendedLength || uint64(p.length) != received {
+		return nil, fmt.Errorf("Extended XMP claims %d bytes; received %d", p.length, received)
+	}
+
+	sort.SliceStable(p.chunks, func(i, j int) bool { return p.chunks[i].offset < p.chunks[j].offset })
+	b := make([]byte, 0, p.length)
+	for _, c := range p.chunks {
+		if c.offset != uint32(len(b)) {
+			return nil, fmt.Errorf("Extended XMP chunk at offset %d; expected %d", c.offset, len(b))
+		}
+		b = append(b, c.data...)
+	}
+	if uint32(len(b)) != p.length {
+		return nil, fmt.Errorf("Extended XMP is %d bytes; expected %d", len(b), p.length)
+	}
+
+	sum := md5.Sum(b)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), guid) {
+		return nil, fmt.Errorf("Extended XMP digest %X does not match GUID %s", sum, guid)
+	}
+	return b, nil
+}
+
+// Resolve reassembles the extended packet named by the main packet's
+// xmpNote:HasExtendedXMP property, and merges its properties into the main
+// packet.  It does nothing if the main packet has no extended packet.
+func (e *Extended) Resolve(p *Packet) error {
+	n, ok := p.Get(NoteNamespace, "HasExtendedXMP")
+	if !ok {
+		return nil
+	}
+	b, err := e.assemble(n.Value)
+	if err != nil {
+		return err
+	}
+	return p.merge(b)
+}
@@ -0,0 +1,337 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+	// NoteNamespace is the namespace of the xmpNote:HasExtendedXMP property
+	NoteNamespace = "http://ns.adobe.com/xmp/note/"
+)
+
+// Kind describes the shape of an XMP property
+type Kind int
+
+const (
+	// Simple properties hold a single value
+	Simple Kind = iota
+
+	// Struct properties hold named fields as children
+	Struct
+
+	// Bag properties hold unordered items as children
+	Bag
+
+	// Seq properties hold ordered items as children
+	Seq
+
+	// Alt properties hold alternative items as children, usually one per
+	// language
+	Alt
+)
+
+var kinds = [...]string{
+	"simple",
+	"struct",
+	"bag",
+	"seq",
+	"alt",
+}
+
+func (k Kind) String() string {
+	return kinds[k]
+}
+
+// Node is a single XMP property, struct field, or array item
+type Node struct {
+	// Namespace is the namespace URI of the property.  It is empty for array
+	// items.
+	Namespace string
+
+	// Name is the local name of the property.  It is empty for array items.
+	Name string
+
+	// Kind describes the shape of the property
+	Kind Kind
+
+	// Value holds the value of a simple property
+	Value string
+
+	// Language is the xml:lang qualifier, as used by the items of language
+	// alternatives
+	Language string
+
+	// Children holds the fields of a struct, or the items of an array, in
+	// document order
+	Children []*Node
+}
+
+// LocalizedText returns the value of the item of a language alternative
+// matching lang, falling back to the "x-default" item and then the first item.
+// For a simple property, its value is returned.
+func (n *Node) LocalizedText(lang string) (string, bool) {
+	if n.Kind == Simple {
+		return n.Value, true
+	}
+	if n.Kind != Alt || len(n.Children) == 0 {
+		return "", false
+	}
+	for _, want := range []string{lang, "x-default"} {
+		for _, c := range n.Children {
+			if strings.EqualFold(c.Language, want) {
+				return c.Value, true
+			}
+		}
+	}
+	return n.Children[0].Value, true
+}
+
+// Packet is a parsed XMP packet
+type Packet struct {
+	// Raw holds the bytes of the main packet
+	Raw []byte
+
+	// ExtendedRaw holds the bytes of the reassembled extended packet, if there
+	// was one and its digest matched the main packet
+	ExtendedRaw []byte
+
+	// Prefixes maps each namespace URI to the prefix the packet declared it
+	// with
+	Prefixes map[string]string
+
+	// Properties holds the top-level properties, in document order.  The
+	// properties of the extended packet follow those of the main packet.
+	Properties []*Node
+}
+
+// Get returns the first top-level property with the provided namespace and
+// name
+func (p *Packet) Get(namespace, name string) (*Node, bool) {
+	for _, n := range p.Properties {
+		if n.Namespace == namespace && n.Name == name {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Walk calls fn with every node in the packet, along with its path, such as
+// "dc:subject[2]" or "exif:Flash/exif:Fired".  Walking stops if fn returns
+// false.
+func (p *Packet) Walk(fn func(path string, n *Node) bool) {
+	for _, n := range p.Properties {
+		if !p.walk(p.qualify(n), n, fn) {
+			return
+		}
+	}
+}
+
+func (p *Packet) walk(path string, n *Node, fn func(path string, n *Node) bool) bool {
+	if !fn(path, n) {
+		return false
+	}
+	for k, c := range n.Children {
+		var childPath string
+		if n.Kind == Struct {
+			childPath = path + "/" + p.qualify(c)
+		} else {
+			childPath = path + "[" + strconv.Itoa(k+1) + "]"
+		}
+		if !p.walk(childPath, c, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Packet) qualify(n *Node) string {
+	if prefix, ok := p.Prefixes[n.Namespace]; ok {
+		return prefix + ":" + n.Name
+	}
+	return n.Namespace + n.Name
+}
+
+// Parse parses the RDF/XML of an XMP packet
+func Parse(b []byte) (*Packet, error) {
+	p := &Packet{Raw: b, Prefixes: map[string]string{}}
+	props, err := p.parse(b)
+	if err != nil {
+		return nil, err
+	}
+	p.Properties = props
+	return p, nil
+}
+
+// merge parses the extended packet, and appends its properties
+func (p *Packet) merge(b []byte) error {
+	props, err := p.parse(b)
+	if err != nil {
+		return err
+	}
+	p.ExtendedRaw = b
+	p.Properties = append(p.Properties, props...)
+	return nil
+}
+
+func (p *Packet) parse(b []byte) ([]*Node, error) {
+	root, err := p.decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	rdf := root.find(rdfNamespace, "RDF")
+	if rdf == nil {
+		return nil, fmt.Errorf("XMP packet has no rdf:RDF element")
+	}
+	props := []*Node{}
+	for _, d := range rdf.children {
+		if d.name.Space == rdfNamespace && d.name.Local == "Description" {
+			props = append(props, parseFields(d)...)
+		}
+	}
+	return props, nil
+}
+
+// element is a minimal DOM node, as XMP needs to look ahead at children to
+// decide the shape of a property.
+type element struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*element
+	text     bytes.Buffer
+}
+
+func (e *element) attr(space, local string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func (e *element) find(space, local string) *element {
+	if e.name.Space == space && e.name.Local == local {
+		return e
+	}
+	for _, c := range e.children {
+		if f := c.find(space, local); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func (p *Packet) decode(b []byte) (*element, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	root := &element{}
+	stack := []*element{root}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Failed to parse XMP packet: %w", err)
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			e := &element{name: t.Name, attrs: t.Attr}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					p.Prefixes[a.Value] = a.Name.Local
+				}
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, e)
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+		}
+	}
+	return root, nil
+}
+
+// isQualifier reports whether an attribute is RDF or XML syntax, rather than
+// a property
+func isQualifier(a xml.Attr) bool {
+	return a.Name.Space == rdfNamespace || a.Name.Space == xmlNamespace || a.Name.Space == "xml" || a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns"
+}
+
+// parseFields returns the properties of an rdf:Description, or of a struct,
+// from both its attributes and its child elements
+func parseFields(e *element) []*Node {
+	nodes := []*Node{}
+	for _, a := range e.attrs {
+		if !isQualifier(a) {
+			nodes = append(nodes, &Node{Namespace: a.Name.Space, Name: a.Name.Local, Kind: Simple, Value: a.Value})
+		}
+	}
+	for _, c := range e.children {
+		nodes = append(nodes, parseProperty(c))
+	}
+	return nodes
+}
+
+func parseProperty(e *element) *Node {
+	n := &Node{Namespace: e.name.Space, Name: e.name.Local}
+	if lang, ok := e.attr(xmlNamespace, "lang"); ok {
+		n.Language = lang
+	}
+
+	if resource, ok := e.attr(rdfNamespace, "resource"); ok {
+		n.Value = resource
+		return n
+	}
+	if parseType, ok := e.attr(rdfNamespace, "parseType"); ok && parseType == "Resource" {
+		n.Kind = Struct
+		n.Children = parseFields(e)
+		return n
+	}
+
+	if len(e.children) == 1 && e.children[0].name.Space == rdfNamespace {
+		c := e.children[0]
+		switch c.name.Local {
+		case "Bag", "Seq", "Alt":
+			n.Kind = map[string]Kind{"Bag": Bag, "Seq": Seq, "Alt": Alt}[c.name.Local]
+			for _, li := range c.children {
+				item := parseProperty(li)
+				item.Namespace, item.Name = "", ""
+				n.Children = append(n.Children, item)
+			}
+			return n
+		case "Description":
+			n.Kind = Struct
+			n.Children = parseFields(c)
+			return n
+		}
+	}
+
+	if len(e.children) != 0 {
+		// Struct fields written without rdf:parseType
+		n.Kind = Struct
+		n.Children = parseFields(e)
+		return n
+	}
+	for _, a := range e.attrs {
+		if !isQualifier(a) {
+			// Struct fields written as attributes
+			n.Kind = Struct
+			n.Children = parseFields(e)
+			return n
+		}
+	}
+
+	n.Value = e.text.String()
+	return n
+}